@@ -0,0 +1,27 @@
+package generate
+
+import (
+	"github.com/go-swagger/go-swagger/generator"
+	"github.com/jessevdk/go-flags"
+)
+
+// CLI generates a command line client for the API
+type CLI struct {
+	Spec       flags.Filename `long:"spec" short:"f" description:"the spec file to use" default:"./swagger.json"`
+	Target     flags.Filename `long:"target" short:"t" default:"./" description:"the base directory for generating the files"`
+	Name       string         `long:"name" short:"A" description:"the name of the application, defaults to a mangled value of info.title"`
+	Operations []string       `long:"operation" short:"O" description:"specify an operation to include, repeat for multiple"`
+	DumpData   bool           `long:"dump-data" description:"when present dumps the json for the template generator instead of generating files"`
+}
+
+// Execute generates the command line client
+func (c *CLI) Execute(args []string) error {
+	return generator.GenerateCLI(
+		c.Name,
+		c.Operations,
+		generator.GenOpts{
+			Spec:     string(c.Spec),
+			Target:   string(c.Target),
+			DumpData: c.DumpData,
+		})
+}
@@ -0,0 +1,29 @@
+package generate
+
+import (
+	"github.com/go-swagger/go-swagger/generator"
+	"github.com/jessevdk/go-flags"
+)
+
+// Docs generates the reference documentation for a swagger spec
+type Docs struct {
+	Spec        flags.Filename `long:"spec" short:"f" description:"the spec file to use" default:"./swagger.json"`
+	Target      flags.Filename `long:"target" short:"t" default:"./" description:"the base directory for generating the files, the docs are written to the docs folder in it"`
+	Name        string         `long:"name" short:"A" description:"the title of the documentation, defaults to info.title"`
+	Format      []string       `long:"format" description:"the format to render, markdown or html, can be repeated and defaults to both"`
+	TemplateDir flags.Filename `long:"template-dir" description:"a directory with a docs folder containing templates that override the builtin ones"`
+	DumpData    bool           `long:"dump-data" description:"when present dumps the json for the template generator instead of generating files"`
+}
+
+// Execute generates the docs
+func (d *Docs) Execute(args []string) error {
+	return generator.GenerateDocs(
+		d.Name,
+		d.Format,
+		generator.GenOpts{
+			Spec:        string(d.Spec),
+			Target:      string(d.Target),
+			TemplateDir: string(d.TemplateDir),
+			DumpData:    d.DumpData,
+		})
+}
@@ -0,0 +1,34 @@
+package generate
+
+import "github.com/go-swagger/go-swagger/generator"
+
+// Fake generates an in-memory implementation of the API for use in integration tests
+type Fake struct {
+	shared
+	Name      string `long:"name" short:"A" description:"the name of the application, defaults to a mangled value of info.title"`
+	Principal string `long:"principal" description:"the model to use for the security principal"`
+	DumpData  bool   `long:"dump-data" description:"when present dumps the json for the template generator instead of generating files"`
+}
+
+// Execute generates the fake
+func (f *Fake) Execute(args []string) error {
+	mappings, err := f.formatMappings()
+	if err != nil {
+		return err
+	}
+	return generator.GenerateFake(
+		f.Name,
+		nil,
+		nil,
+		generator.GenOpts{
+			Spec:           string(f.Spec),
+			Target:         string(f.Target),
+			APIPackage:     f.APIPackage,
+			ModelPackage:   f.ModelPackage,
+			ServerPackage:  f.ServerPackage,
+			ClientPackage:  f.ClientPackage,
+			Principal:      f.Principal,
+			DumpData:       f.DumpData,
+			FormatMappings: mappings,
+		})
+}
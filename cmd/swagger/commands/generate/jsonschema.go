@@ -0,0 +1,27 @@
+package generate
+
+import (
+	"github.com/go-swagger/go-swagger/generator"
+	"github.com/jessevdk/go-flags"
+)
+
+// JSONSchema exports the definitions of a spec as json schema documents
+type JSONSchema struct {
+	Spec     flags.Filename `long:"spec" short:"f" description:"the spec file to use" default:"./swagger.json"`
+	Target   flags.Filename `long:"target" short:"t" default:"./" description:"the base directory for generating the files, the schemas are written to the jsonschema folder in it"`
+	Models   []string       `long:"model" short:"M" description:"specify a model to include, repeat for multiple"`
+	Inline   bool           `long:"inline" description:"copy the referenced definitions into every schema instead of referring to their files"`
+	DumpData bool           `long:"dump-data" description:"when present dumps the json schemas instead of generating files"`
+}
+
+// Execute generates the json schemas
+func (j *JSONSchema) Execute(args []string) error {
+	return generator.GenerateJSONSchema(
+		j.Models,
+		j.Inline,
+		generator.GenOpts{
+			Spec:     string(j.Spec),
+			Target:   string(j.Target),
+			DumpData: j.DumpData,
+		})
+}
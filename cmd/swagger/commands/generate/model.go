@@ -9,6 +9,7 @@ import (
 // Model the generate model file command
 type Model struct {
 	shared
+	modelHelpers
 	Name        []string `long:"name" short:"n" required:"true" description:"the model to generate"`
 	NoValidator bool     `long:"skip-validator" description:"when present will not generate a model validator"`
 	NoStruct    bool     `long:"skip-struct" description:"when present will not generate the model struct"`
@@ -20,17 +21,24 @@ func (m *Model) Execute(args []string) error {
 	if m.DumpData && len(m.Name) > 1 {
 		return errors.New("only 1 model at a time is supported for dumping data")
 	}
+	mappings, err := m.formatMappings()
+	if err != nil {
+		return err
+	}
 	return generator.GenerateModel(
 		m.Name,
 		!m.NoStruct,
 		!m.NoValidator,
 		generator.GenOpts{
-			Spec:          string(m.Spec),
-			Target:        string(m.Target),
-			APIPackage:    m.APIPackage,
-			ModelPackage:  m.ModelPackage,
-			ServerPackage: m.ServerPackage,
-			ClientPackage: m.ClientPackage,
-			DumpData:      m.DumpData,
+			Spec:           string(m.Spec),
+			Target:         string(m.Target),
+			APIPackage:     m.APIPackage,
+			ModelPackage:   m.ModelPackage,
+			ServerPackage:  m.ServerPackage,
+			ClientPackage:  m.ClientPackage,
+			DumpData:       m.DumpData,
+			Concurrency:    m.Concurrency,
+			FormatMappings: mappings,
+			ModelHelpers:   m.helpers(),
 		})
 }
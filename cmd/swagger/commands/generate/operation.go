@@ -22,19 +22,25 @@ func (o *Operation) Execute(args []string) error {
 	if o.DumpData && len(o.Name) > 1 {
 		return errors.New("only 1 operation at a time is supported for dumping data")
 	}
+	mappings, err := o.formatMappings()
+	if err != nil {
+		return err
+	}
 	return generator.GenerateServerOperation(
 		o.Name,
 		o.Tags,
 		!o.NoHandler,
 		!o.NoStruct,
 		generator.GenOpts{
-			Spec:          string(o.Spec),
-			Target:        string(o.Target),
-			APIPackage:    o.APIPackage,
-			ModelPackage:  o.ModelPackage,
-			ServerPackage: o.ServerPackage,
-			ClientPackage: o.ClientPackage,
-			Principal:     o.Principal,
-			DumpData:      o.DumpData,
+			Spec:           string(o.Spec),
+			Target:         string(o.Target),
+			APIPackage:     o.APIPackage,
+			ModelPackage:   o.ModelPackage,
+			ServerPackage:  o.ServerPackage,
+			ClientPackage:  o.ClientPackage,
+			Principal:      o.Principal,
+			DumpData:       o.DumpData,
+			Concurrency:    o.Concurrency,
+			FormatMappings: mappings,
 		})
 }
@@ -0,0 +1,27 @@
+package generate
+
+import (
+	"github.com/go-swagger/go-swagger/generator"
+	"github.com/jessevdk/go-flags"
+)
+
+// Proto generates protocol buffers messages for the definitions of a spec
+type Proto struct {
+	Spec     flags.Filename `long:"spec" short:"f" description:"the spec file to use" default:"./swagger.json"`
+	Target   flags.Filename `long:"target" short:"t" default:"./" description:"the base directory for generating the files, the proto file is written to the proto folder in it"`
+	Package  string         `long:"package" short:"p" description:"the protobuf package, defaults to a mangled value of info.title"`
+	Models   []string       `long:"model" short:"M" description:"specify a model to include, repeat for multiple"`
+	DumpData bool           `long:"dump-data" description:"when present dumps the json for the template generator instead of generating files"`
+}
+
+// Execute generates the proto file
+func (p *Proto) Execute(args []string) error {
+	return generator.GenerateProto(
+		p.Package,
+		p.Models,
+		generator.GenOpts{
+			Spec:     string(p.Spec),
+			Target:   string(p.Target),
+			DumpData: p.DumpData,
+		})
+}
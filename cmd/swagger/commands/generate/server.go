@@ -13,13 +13,38 @@ type shared struct {
 	ClientPackage string         `long:"client-package" short:"c" description:"the package to save the client specific code" default:"client"`
 	TestPackage   string         `long:"test-package" short:"T" description:"the package to save the test specific code" default:"test"`
 	Target        flags.Filename `long:"target" short:"t" default:"./" description:"the base directory for generating the files"`
+	Concurrency   int            `long:"concurrency" description:"the number of models and operations to render at the same time, defaults to the number of CPUs"`
+	FormatMapping flags.Filename `long:"format-mapping" description:"a yaml or json file with the go types to use for custom string formats"`
 	// TemplateDir  flags.Filename `long:"template-dir"`
 
 }
 
+// modelHelpers the flags for the optional helpers of the generated models
+type modelHelpers struct {
+	WithEqual    bool   `long:"with-equal" description:"generates an Equal method for every model"`
+	WithDeepCopy bool   `long:"with-deep-copy" description:"generates a DeepCopy method for every model"`
+	WithBuilder  string `long:"with-builder" description:"generates constructors for every model, either fluent or options"`
+}
+
+func (h *modelHelpers) helpers() generator.ModelHelpers {
+	return generator.ModelHelpers{
+		Equal:    h.WithEqual,
+		DeepCopy: h.WithDeepCopy,
+		Builder:  h.WithBuilder,
+	}
+}
+
+func (s *shared) formatMappings() ([]generator.FormatMapping, error) {
+	if s.FormatMapping == "" {
+		return nil, nil
+	}
+	return generator.LoadFormatMappings(string(s.FormatMapping))
+}
+
 // Server the command to generate an entire server application
 type Server struct {
 	shared
+	modelHelpers
 	Name           string   `long:"name" short:"A" description:"the name of the application, defaults to a mangled value of info.title"`
 	Operations     []string `long:"operation" short:"O" description:"specify an operation to include, repeat for multiple"`
 	Tags           []string `long:"tags" description:"the tags to include, if not specified defaults to all"`
@@ -29,19 +54,29 @@ type Server struct {
 	SkipOperations bool     `long:"skip-operations" description:"no operations will be generated when this flag is specified"`
 	SkipSupport    bool     `long:"skip-support" description:"no supporting files will be generated when this flag is specified"`
 	IncludeUI      bool     `long:"with-ui" description:"when generating a main package it uses a middleware that also serves a swagger-ui for the swagger json"`
+	WithServices   bool     `long:"with-services" description:"generates an interface per tag with a method for every operation of that tag"`
 }
 
 // Execute runs this command
 func (s *Server) Execute(args []string) error {
+	mappings, err := s.formatMappings()
+	if err != nil {
+		return err
+	}
+
 	opts := generator.GenOpts{
-		Spec:          string(s.Spec),
-		Target:        string(s.Target),
-		APIPackage:    s.APIPackage,
-		ModelPackage:  s.ModelPackage,
-		ServerPackage: s.ServerPackage,
-		ClientPackage: s.ClientPackage,
-		TestPackage:   s.TestPackage,
-		Principal:     s.Principal,
+		Spec:           string(s.Spec),
+		Target:         string(s.Target),
+		APIPackage:     s.APIPackage,
+		ModelPackage:   s.ModelPackage,
+		ServerPackage:  s.ServerPackage,
+		ClientPackage:  s.ClientPackage,
+		TestPackage:    s.TestPackage,
+		Principal:      s.Principal,
+		Concurrency:    s.Concurrency,
+		FormatMappings: mappings,
+		Services:       s.WithServices,
+		ModelHelpers:   s.helpers(),
 	}
 
 	if !s.SkipModels && (len(s.Models) > 0 || len(s.Operations) == 0) {
@@ -5,29 +5,36 @@ import "github.com/go-swagger/go-swagger/generator"
 // Support generates the supporting files
 type Support struct {
 	shared
-	Name       string   `long:"name" short:"A" description:"the name of the application, defaults to a mangled value of info.title"`
-	Operations []string `long:"operation" short:"O" description:"specify an operation to include, repeat for multiple"`
-	Principal  string   `long:"principal" description:"the model to use for the security principal"`
-	Models     []string `long:"model" short:"M" description:"specify a model to include, repeat for multiple"`
-	DumpData   bool     `long:"dump-data" description:"when present dumps the json for the template generator instead of generating files"`
-	IncludeUI  bool     `long:"with-ui" description:"when generating a main package it uses a middleware that also serves a swagger-ui for the swagger json"`
+	Name         string   `long:"name" short:"A" description:"the name of the application, defaults to a mangled value of info.title"`
+	Operations   []string `long:"operation" short:"O" description:"specify an operation to include, repeat for multiple"`
+	Principal    string   `long:"principal" description:"the model to use for the security principal"`
+	Models       []string `long:"model" short:"M" description:"specify a model to include, repeat for multiple"`
+	DumpData     bool     `long:"dump-data" description:"when present dumps the json for the template generator instead of generating files"`
+	IncludeUI    bool     `long:"with-ui" description:"when generating a main package it uses a middleware that also serves a swagger-ui for the swagger json"`
+	WithServices bool     `long:"with-services" description:"generates an interface per tag with a method for every operation of that tag"`
 }
 
 // Execute generates the supporting files file
 func (s *Support) Execute(args []string) error {
+	mappings, err := s.formatMappings()
+	if err != nil {
+		return err
+	}
 	return generator.GenerateSupport(
 		s.Name,
 		nil,
 		nil,
 		s.IncludeUI,
 		generator.GenOpts{
-			Spec:          string(s.Spec),
-			Target:        string(s.Target),
-			APIPackage:    s.APIPackage,
-			ModelPackage:  s.ModelPackage,
-			ServerPackage: s.ServerPackage,
-			ClientPackage: s.ClientPackage,
-			Principal:     s.Principal,
-			DumpData:      s.DumpData,
+			Spec:           string(s.Spec),
+			Target:         string(s.Target),
+			APIPackage:     s.APIPackage,
+			ModelPackage:   s.ModelPackage,
+			ServerPackage:  s.ServerPackage,
+			ClientPackage:  s.ClientPackage,
+			Principal:      s.Principal,
+			DumpData:       s.DumpData,
+			FormatMappings: mappings,
+			Services:       s.WithServices,
 		})
 }
@@ -25,15 +25,21 @@ type Test struct {
 
 // Execute runs this command
 func (t *Test) Execute(args []string) error {
+	mappings, err := t.formatMappings()
+	if err != nil {
+		return err
+	}
+
 	opts := generator.GenOpts{
-		Spec:          string(t.Spec),
-		Target:        string(t.Target),
-		APIPackage:    t.APIPackage,
-		ModelPackage:  t.ModelPackage,
-		ServerPackage: t.ServerPackage,
-		ClientPackage: t.ClientPackage,
-		TestPackage:   t.TestPackage,
-		Principal:     t.Principal,
+		Spec:           string(t.Spec),
+		Target:         string(t.Target),
+		APIPackage:     t.APIPackage,
+		ModelPackage:   t.ModelPackage,
+		ServerPackage:  t.ServerPackage,
+		ClientPackage:  t.ClientPackage,
+		TestPackage:    t.TestPackage,
+		Principal:      t.Principal,
+		FormatMappings: mappings,
 	}
 
 
@@ -42,6 +42,21 @@ It aims to represent the contract of your API with a language agnostic descripti
 		case "spec":
 			cmd.ShortDescription = "generate a swagger spec document from a go application"
 			cmd.LongDescription = cmd.ShortDescription
+		case "fake":
+			cmd.ShortDescription = "generate an in-memory implementation of the api for integration tests"
+			cmd.LongDescription = cmd.ShortDescription
+		case "docs":
+			cmd.ShortDescription = "generate markdown and html reference documentation for a swagger spec"
+			cmd.LongDescription = cmd.ShortDescription
+		case "cli":
+			cmd.ShortDescription = "generate a command line client for the api"
+			cmd.LongDescription = cmd.ShortDescription
+		case "proto":
+			cmd.ShortDescription = "generate protocol buffers messages for the definitions of a swagger spec"
+			cmd.LongDescription = cmd.ShortDescription
+		case "jsonschema":
+			cmd.ShortDescription = "export the definitions of a swagger spec as json schema documents"
+			cmd.LongDescription = cmd.ShortDescription
 		}
 	}
 
@@ -27,6 +27,8 @@ const (
 	typeFailWithDataNoIn      = "%s must be of type %s: %q"
 	typeFailWithErrorNoIn     = "%s must be of type %s, because: %s"
 	requiredFailNoIn          = "%s is required"
+	readOnlyFail              = "%s in %s is read only"
+	readOnlyFailNoIn          = "%s is read only"
 	tooLongMessageNoIn        = "%s should be at most %d chars long"
 	tooShortMessageNoIn       = "%s should be at least %d chars long"
 	patternFailNoIn           = "%s should match '%s'"
@@ -355,6 +357,22 @@ func Required(name, in string) *Validation {
 	}
 }
 
+// ReadOnly error for when a read only property is sent in a request
+func ReadOnly(name, in string) *Validation {
+	var msg string
+	if in == "" {
+		msg = fmt.Sprintf(readOnlyFailNoIn, name)
+	} else {
+		msg = fmt.Sprintf(readOnlyFail, name, in)
+	}
+	return &Validation{
+		code:    422,
+		Name:    name,
+		In:      in,
+		message: msg,
+	}
+}
+
 // TooLong error for when a string is too long
 func TooLong(name, in string, max int64) *Validation {
 	var msg string
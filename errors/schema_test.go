@@ -133,6 +133,16 @@ func TestSchemaErrors(t *testing.T) {
 	assert.EqualValues(t, 422, err.Code())
 	assert.Equal(t, "something is required", err.Error())
 
+	err = ReadOnly("something", "body")
+	assert.Error(t, err)
+	assert.EqualValues(t, 422, err.Code())
+	assert.Equal(t, "something in body is read only", err.Error())
+
+	err = ReadOnly("something", "")
+	assert.Error(t, err)
+	assert.EqualValues(t, 422, err.Code())
+	assert.Equal(t, "something is read only", err.Error())
+
 	err = TooLong("something", "query", 5)
 	assert.Error(t, err)
 	assert.EqualValues(t, 422, err.Code())
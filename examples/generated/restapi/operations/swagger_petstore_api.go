@@ -318,10 +318,17 @@ func (s *SwaggerPetstoreAPI) HandlerFor(operationID string) (http.Handler, bool)
 	return h, ok
 }
 
-func (s *SwaggerPetstoreAPI) initHandlerCache() {
+// Context returns the middleware context of the API,
+// it can be configured before the API is served, like with SetCORS
+func (s *SwaggerPetstoreAPI) Context() *middleware.Context {
 	if s.context == nil {
 		s.context = middleware.NewRoutableContext(s.spec, s, nil)
 	}
+	return s.context
+}
+
+func (s *SwaggerPetstoreAPI) initHandlerCache() {
+	s.Context()
 
 	s.handlers = make(map[string]http.Handler)
 
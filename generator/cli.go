@@ -0,0 +1,361 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/go-swagger/go-swagger/swag"
+)
+
+var cliTemplate *template.Template
+
+func init() {
+	bc, _ := Asset("templates/cli/main.gotmpl")
+	cliTemplate = template.Must(template.New("cli").Parse(string(bc)))
+}
+
+// GenerateCLI generates a main package for a command line client of an API.
+// Every operation becomes a command, grouped in a command per tag.
+func GenerateCLI(name string, operationIDs []string, opts GenOpts) error {
+	_, specDoc, err := loadSpec(opts)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		if specDoc.Spec().Info != nil && specDoc.Spec().Info.Title != "" {
+			name = swag.ToGoName(specDoc.Spec().Info.Title)
+		} else {
+			name = "swagger"
+		}
+	}
+
+	generator := cliGenerator{
+		Name:         name,
+		SpecDoc:      specDoc,
+		OperationIDs: operationIDs,
+		Target:       opts.Target,
+		DumpData:     opts.DumpData,
+		out:          newOutput(opts),
+	}
+	return generator.Generate()
+}
+
+type cliGenerator struct {
+	Name         string
+	SpecDoc      *spec.Document
+	OperationIDs []string
+	Target       string
+	DumpData     bool
+	out          *output
+}
+
+func (c *cliGenerator) Generate() error {
+	cli := c.makeCodegenCLI()
+
+	if c.DumpData {
+		bb, _ := json.MarshalIndent(swag.ToDynamicJSON(cli), "", "  ")
+		fmt.Fprintln(os.Stdout, string(bb))
+		return nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := cliTemplate.Execute(buf, cli); err != nil {
+		return err
+	}
+	c.out.Info("rendered cli template", cli.Name)
+	return c.out.writeToFile(filepath.Join(c.Target, "cmd", cli.Name), "main", buf.Bytes())
+}
+
+type genCLI struct {
+	AppName      string
+	HumanAppName string
+	Name         string
+	EnvPrefix    string
+	SwaggerJSON  string
+	Groups       []genCLIGroup
+	Commands     []genCLICommand
+	APIKeys      []genCLIAPIKey
+	BasicAuth    bool
+}
+
+// genCLIGroup is the command for a tag, the operations with that tag are its subcommands
+type genCLIGroup struct {
+	Name        string
+	ClassName   string
+	Command     string
+	Description string
+	Commands    []genCLICommand
+}
+
+type genCLICommand struct {
+	ID          string
+	ClassName   string
+	Field       string
+	Command     string
+	Description string
+	Method      string
+	Path        string
+	Params      []genCLIParam
+	Body        *genCLIParam
+}
+
+type genCLIParam struct {
+	Name        string
+	Field       string
+	Flag        string
+	GoType      string
+	Description string
+	Default     string
+	Required    bool
+	Enum        []string
+	IsSet       string
+}
+
+// genCLIAPIKey is a flag for the value of an api key security scheme
+type genCLIAPIKey struct {
+	Name   string
+	Field  string
+	Flag   string
+	Env    string
+	In     string
+	Scheme string
+}
+
+func (c *cliGenerator) makeCodegenCLI() genCLI {
+	sw := c.SpecDoc.Spec()
+	jsonb, _ := json.MarshalIndent(sw, "", "  ")
+
+	cli := genCLI{
+		AppName:      swag.ToGoName(c.Name),
+		HumanAppName: swag.ToHumanNameLower(c.Name),
+		Name:         swag.ToCommandName(c.Name + "Cli"),
+		EnvPrefix:    strings.ToUpper(swag.ToFileName(c.Name)),
+		SwaggerJSON:  fmt.Sprintf("%#v", jsonb),
+	}
+
+	var schemeNames []string
+	for k := range sw.SecurityDefinitions {
+		schemeNames = append(schemeNames, k)
+	}
+	sort.Strings(schemeNames)
+	for _, k := range schemeNames {
+		scheme := sw.SecurityDefinitions[k]
+		switch scheme.Type {
+		case "basic":
+			cli.BasicAuth = true
+		case "apiKey":
+			cli.APIKeys = append(cli.APIKeys, genCLIAPIKey{
+				Name:   scheme.Name,
+				Field:  swag.ToGoName(k),
+				Flag:   swag.ToCommandName(k),
+				Env:    cli.EnvPrefix + "_" + strings.ToUpper(swag.ToFileName(k)),
+				In:     scheme.In,
+				Scheme: k,
+			})
+		}
+	}
+
+	groups := make(map[string]*genCLIGroup)
+	for _, t := range sw.Tags {
+		groups[t.Name] = &genCLIGroup{Description: t.Description}
+	}
+	for method, paths := range c.SpecDoc.Operations() {
+		for path, op := range paths {
+			if op.ID == "" || !c.includes(op.ID) {
+				continue
+			}
+			cmd := c.makeCLICommand(method, path, op)
+			if len(op.Tags) == 0 {
+				cli.Commands = append(cli.Commands, cmd)
+				continue
+			}
+			for _, t := range op.Tags {
+				if _, ok := groups[t]; !ok {
+					groups[t] = &genCLIGroup{}
+				}
+				groups[t].Commands = append(groups[t].Commands, cmd)
+			}
+		}
+	}
+	sort.Sort(genCLICommandSlice(cli.Commands))
+
+	var tagNames []string
+	for k, v := range groups {
+		if len(v.Commands) > 0 {
+			tagNames = append(tagNames, k)
+		}
+	}
+	sort.Strings(tagNames)
+	for _, k := range tagNames {
+		group := groups[k]
+		group.Name = k
+		group.ClassName = swag.ToJSONName(k) + "Commands"
+		group.Command = swag.ToCommandName(k)
+		if group.Description == "" {
+			group.Description = "operations tagged with " + k
+		}
+		sort.Sort(genCLICommandSlice(group.Commands))
+		cli.Groups = append(cli.Groups, *group)
+	}
+	return cli
+}
+
+func (c *cliGenerator) includes(operationID string) bool {
+	if len(c.OperationIDs) == 0 {
+		return true
+	}
+	for _, id := range c.OperationIDs {
+		if id == operationID {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cliGenerator) makeCLICommand(method, path string, op *spec.Operation) genCLICommand {
+	cmd := genCLICommand{
+		ID:          op.ID,
+		ClassName:   swag.ToJSONName(op.ID) + "Command",
+		Field:       swag.ToGoName(op.ID),
+		Command:     swag.ToCommandName(op.ID),
+		Description: op.Summary,
+		Method:      strings.ToUpper(method),
+		Path:        path,
+	}
+	if cmd.Description == "" {
+		cmd.Description = swag.ToHumanNameLower(op.ID)
+	}
+
+	var params []spec.Parameter
+	if pi, ok := c.SpecDoc.AllPaths()[path]; ok {
+		params = append(params, pi.Parameters...)
+	}
+	params = append(params, op.Parameters...)
+	seen := make(map[string]int)
+	for _, p := range params {
+		// the commands are generated from the parameters, so references to shared parameters need to be resolved
+		if ref := p.Ref.GetURL(); ref != nil {
+			if rp, ok := c.SpecDoc.Spec().Parameters[filepath.Base(ref.Fragment)]; ok {
+				p = rp
+			}
+		}
+		param, ok := makeCLIParam(p)
+		if !ok {
+			continue
+		}
+		if p.In == "body" {
+			cmd.Body = &param
+			continue
+		}
+		// operation parameters override the parameters of the path
+		if idx, ok := seen[p.In+"."+p.Name]; ok {
+			cmd.Params[idx] = param
+			continue
+		}
+		seen[p.In+"."+p.Name] = len(cmd.Params)
+		cmd.Params = append(cmd.Params, param)
+	}
+	return cmd
+}
+
+// makeCLIParam creates the flag for a parameter, file parameters can't be sent with the client runtime yet
+func makeCLIParam(p spec.Parameter) (genCLIParam, bool) {
+	param := genCLIParam{
+		Name:        p.Name,
+		Field:       swag.ToGoName(p.Name),
+		Flag:        swag.ToCommandName(p.Name),
+		Description: cliTagText(p.Description),
+		Required:    p.Required,
+	}
+	if p.In == "body" {
+		param.Field = "Body"
+		param.Flag = "body"
+		param.GoType = "flags.Filename"
+		param.IsSet = "c.Body != \"\""
+		desc := "a json file with the request body, - reads it from stdin"
+		if param.Description != "" {
+			desc = param.Description + " (" + desc + ")"
+		}
+		param.Description = desc
+		return param, true
+	}
+
+	tpe, ok := cliFlagType(p.Type, p.Format, p.Items)
+	if !ok {
+		return param, false
+	}
+	param.GoType = tpe
+	if param.Description == "" {
+		param.Description = "the " + p.Name + " " + p.In + " parameter"
+	}
+	if p.Default != nil && !strings.HasPrefix(tpe, "[]") {
+		param.Default = cliTagText(fmt.Sprint(p.Default))
+	}
+	if !strings.HasPrefix(tpe, "[]") {
+		for _, v := range p.Enum {
+			param.Enum = append(param.Enum, fmt.Sprint(v))
+		}
+	}
+	if len(param.Enum) > 0 {
+		param.Description += ", one of " + cliTagText(strings.Join(param.Enum, ", "))
+	}
+
+	switch {
+	case strings.HasPrefix(tpe, "[]"):
+		param.IsSet = "len(c." + param.Field + ") > 0"
+	case tpe == "string":
+		param.IsSet = "c." + param.Field + " != \"\""
+	case tpe == "bool":
+		param.IsSet = "c." + param.Field
+	default:
+		param.IsSet = "c." + param.Field + " != 0"
+	}
+	return param, true
+}
+
+// cliFlagType returns the go type of the flag for a simple schema
+func cliFlagType(tpe, format string, items *spec.Items) (string, bool) {
+	switch tpe {
+	case "string":
+		return "string", true
+	case "boolean":
+		return "bool", true
+	case "integer":
+		if format == "int32" {
+			return "int32", true
+		}
+		return "int64", true
+	case "number":
+		if format == "float" {
+			return "float32", true
+		}
+		return "float64", true
+	case "array":
+		if items == nil || items.Type == "array" {
+			return "", false
+		}
+		elem, ok := cliFlagType(items.Type, items.Format, nil)
+		return "[]" + elem, ok
+	}
+	return "", false
+}
+
+// cliTagText makes a text safe to use as the value of a struct tag
+func cliTagText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.NewReplacer("\\", "/", "\"", "'", "`", "'").Replace(text)
+}
+
+type genCLICommandSlice []genCLICommand
+
+func (g genCLICommandSlice) Len() int           { return len(g) }
+func (g genCLICommandSlice) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g genCLICommandSlice) Less(i, j int) bool { return g[i].Command < g[j].Command }
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCLI(t *testing.T) {
+	opts := testGenOpts("../fixtures/petstores/petstore-expanded.json", "api")
+	fs := NewMemFileSystem()
+	opts.FileSystem = fs
+	opts.Diagnostics = func(Diagnostic) {}
+	if !assert.NoError(t, GenerateCLI("petstore", nil, opts)) {
+		return
+	}
+	files := fs.Files()
+	if !assert.Equal(t, []string{"api/cmd/petstore-cli/main.go"}, generatedPaths(files)) {
+		return
+	}
+
+	// the server runs on its own goroutine, so it hands over what it needs to check
+	type request struct {
+		method string
+		path   string
+		query  url.Values
+	}
+	received := make(chan request, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		received <- request{method: r.Method, path: r.URL.Path, query: r.URL.Query()}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte(`[{"id":1,"name":"fido"}]`))
+	}))
+	defer server.Close()
+
+	buildGenerated(t, files, func(dir string) {
+		run := func(args ...string) (string, error) {
+			out, err := exec.Command(filepath.Join(dir, "cmd"), args...).CombinedOutput()
+			return string(out), err
+		}
+
+		help, _ := run("--help")
+		assert.Contains(t, help, "Sends requests to the petstore api")
+		assert.Contains(t, help, "PETSTORE_HOST")
+		for _, command := range []string{"add-pet", "delete-pet", "find-pet-by-id", "find-pets"} {
+			assert.Contains(t, help, command)
+		}
+
+		out, err := run("--scheme", "http", "--host", strings.TrimPrefix(server.URL, "http://"), "find-pets", "--limit", "2", "--tags", "dog")
+		if assert.NoError(t, err, out) && assert.Len(t, received, 1) {
+			req := <-received
+			assert.Equal(t, "GET", req.method)
+			assert.Equal(t, "/api/pets", req.path)
+			assert.Equal(t, "2", req.query.Get("limit"))
+			assert.Equal(t, "dog", req.query.Get("tags"))
+			assert.Equal(t, "[\n  {\n    \"id\": 1,\n    \"name\": \"fido\"\n  }\n]\n", out)
+		}
+
+		out, err = run("--output", "yaml", "--scheme", "http", "--host", strings.TrimPrefix(server.URL, "http://"), "find-pets")
+		if assert.NoError(t, err, out) {
+			assert.Equal(t, "- id: 1\n  name: fido\n", out)
+		}
+
+		_, err = run("find-pet-by-id")
+		assert.Error(t, err, "the id is required")
+	})
+}
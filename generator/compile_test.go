@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buildGenerated builds the files of a generated package in a directory inside this package,
+// so the generated code resolves its imports the same way the generator does.
+// A main package is built as a command named cmd in that directory, fn gets to use it before the directory is removed.
+func buildGenerated(t *testing.T, files []GeneratedFile, fn func(dir string)) bool {
+	dir, err := ioutil.TempDir(".", "_generated")
+	if !assert.NoError(t, err) {
+		return false
+	}
+	defer os.RemoveAll(dir)
+
+	for _, f := range files {
+		if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, filepath.Base(f.Path)), f.Content, 0644)) {
+			return false
+		}
+	}
+
+	cmd := exec.Command("go", "build", "-o", "cmd", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if !assert.NoError(t, err, "%s", out) {
+		return false
+	}
+	if fn != nil {
+		fn(dir)
+	}
+	return true
+}
+
+func compileGenerated(t *testing.T, files []GeneratedFile) bool {
+	return buildGenerated(t, files, nil)
+}
+
+// generateServer generates the server for a spec into a directory inside this package and builds its packages,
+// generate gets to add to the generated code.
+// The gopath points at the repository while the code is generated, so the imports of the generated code
+// resolve to that directory. fn gets the directory before it's removed.
+// The main package isn't built, it refers to the operations without a tag as if it were the api package.
+func generateServer(t *testing.T, opts GenOpts, generate func(GenOpts) error, fn func(dir string)) {
+	dir, err := ioutil.TempDir(".", "_generated")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	root, err := filepath.Abs("..")
+	if !assert.NoError(t, err) {
+		return
+	}
+	gopath, err := ioutil.TempDir("", "generator")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(gopath)
+	link := filepath.Join(gopath, "src", "github.com", "go-swagger", "go-swagger")
+	if !assert.NoError(t, os.MkdirAll(filepath.Dir(link), 0755)) || !assert.NoError(t, os.Symlink(root, link)) {
+		return
+	}
+
+	old := os.Getenv("GOPATH")
+	os.Setenv("GOPATH", gopath)
+	opts.Target = filepath.Join(link, "generator", filepath.Base(dir))
+	opts.Diagnostics = func(Diagnostic) {}
+	err = GenerateModel(nil, true, true, opts)
+	if err == nil {
+		err = GenerateServerOperation(nil, nil, true, true, opts)
+	}
+	if err == nil {
+		err = GenerateSupport("", nil, nil, false, opts)
+	}
+	if err == nil && generate != nil {
+		err = generate(opts)
+	}
+	os.Setenv("GOPATH", old)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cmd := exec.Command("go", "build", "./models/...", "./restapi/...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); !assert.NoError(t, err, "%s", out) {
+		return
+	}
+	fn(dir)
+}
+
+// runGenerated runs a main package inside the directory of a generated server, the code can import
+// the generated packages from github.com/go-swagger/go-swagger/generator/{{dir}}
+func runGenerated(dir, code string) (string, error) {
+	check := filepath.Join(dir, "check")
+	if err := os.MkdirAll(check, 0755); err != nil {
+		return "", err
+	}
+	code = strings.Replace(code, "{{dir}}", filepath.Base(dir), -1)
+	if err := ioutil.WriteFile(filepath.Join(check, "main.go"), []byte(code), 0644); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("go", "run", "./check")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestCompileModels(t *testing.T) {
+	for _, fixture := range []string{
+		"../fixtures/codegen/tasklist.basic.yml",
+		"../fixtures/petstores/petstore-expanded.json",
+	} {
+		files, _, err := RenderModel(nil, true, true, testGenOpts(fixture, "generated"))
+		if assert.NoError(t, err, fixture) {
+			compileGenerated(t, files)
+		}
+	}
+}
+
+func TestCompileModelHelpers(t *testing.T) {
+	// the expanded petstore has a NewPet definition, so the constructor of a Pet can't be NewPet
+	for _, builder := range []string{BuilderFluent, BuilderOptions} {
+		opts := testGenOpts("../fixtures/petstores/petstore-expanded.json", "generated")
+		opts.ModelHelpers = ModelHelpers{Equal: true, DeepCopy: true, Builder: builder}
+		files, _, err := RenderModel(nil, true, true, opts)
+		if assert.NoError(t, err, builder) {
+			compileGenerated(t, files)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// GenerationError is the failure to generate the files for a single model or operation
+type GenerationError struct {
+	Name string
+	Err  error
+}
+
+func (g *GenerationError) Error() string {
+	return g.Name + ": " + g.Err.Error()
+}
+
+// GenerationErrors collects all the failures of a generation run, in the order the items were requested
+type GenerationErrors []*GenerationError
+
+func (g GenerationErrors) Error() string {
+	msgs := make([]string, 0, len(g))
+	for _, e := range g {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// generationJob renders the files for a single model or operation
+type generationJob struct {
+	Name string
+	Run  func(*output) error
+}
+
+// runJobs executes the jobs with a bounded pool of workers.
+// The diagnostics of every job are buffered and reported in the order of the jobs,
+// so the output doesn't depend on the order in which the workers finish.
+func runJobs(out *output, concurrency int, jobs []generationJob) error {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	if concurrency > len(jobs) {
+		concurrency = len(jobs)
+	}
+
+	diags := make([][]Diagnostic, len(jobs))
+	errs := make([]error, len(jobs))
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				jobOut := &output{fs: out.fs, report: collectDiagnostics(&diags[i])}
+				errs[i] = jobs[i].Run(jobOut)
+			}
+		}()
+	}
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	var result GenerationErrors
+	for i, job := range jobs {
+		for _, d := range diags[i] {
+			out.report(d)
+		}
+		if errs[i] != nil {
+			out.report(Diagnostic{Level: DiagnosticError, Message: "failed to generate", Target: job.Name, Err: errs[i]})
+			result = append(result, &GenerationError{Name: job.Name, Err: errs[i]})
+		}
+	}
+	if len(result) > 0 {
+		return result
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jobsRun struct {
+	files     []GeneratedFile
+	diags     []Diagnostic
+	err       error
+	maxActive int32
+}
+
+// runTestJobs runs jobs that write a file and report a diagnostic each, the jobs in failing return an error
+func runTestJobs(concurrency, count int, failing ...int) jobsRun {
+	fails := make(map[int]bool)
+	for _, i := range failing {
+		fails[i] = true
+	}
+
+	var result jobsRun
+	var active int32
+	fs := NewMemFileSystem()
+	out := &output{fs: fs, report: collectDiagnostics(&result.diags)}
+
+	var jobs []generationJob
+	for i := 0; i < count; i++ {
+		i := i
+		name := fmt.Sprintf("job%02d", i)
+		jobs = append(jobs, generationJob{Name: name, Run: func(out *output) error {
+			n := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+			for {
+				max := atomic.LoadInt32(&result.maxActive)
+				if n <= max || atomic.CompareAndSwapInt32(&result.maxActive, max, n) {
+					break
+				}
+			}
+			// the later jobs finish first
+			time.Sleep(time.Duration(count-i) * time.Millisecond)
+
+			out.Info("started", name)
+			if fails[i] {
+				return errors.New("failed " + name)
+			}
+			out.Info("generated", name)
+			return out.writeFile("jobs", name+".txt", []byte(name))
+		}})
+	}
+	result.err = runJobs(out, concurrency, jobs)
+	result.files = fs.Files()
+	return result
+}
+
+func TestRunJobsDeterministic(t *testing.T) {
+	expected := runTestJobs(1, 10, 7, 2)
+	assert.Len(t, expected.files, 8)
+	assert.EqualValues(t, 1, expected.maxActive)
+
+	for _, concurrency := range []int{0, 2, 4, 10, 20} {
+		actual := runTestJobs(concurrency, 10, 7, 2)
+		assert.Equal(t, expected.files, actual.files, "concurrency %d", concurrency)
+		assert.Equal(t, expected.diags, actual.diags, "concurrency %d", concurrency)
+		assert.Equal(t, expected.err, actual.err, "concurrency %d", concurrency)
+	}
+}
+
+func TestRunJobsBound(t *testing.T) {
+	for _, concurrency := range []int{1, 3, 5} {
+		result := runTestJobs(concurrency, 12)
+		assert.NoError(t, result.err)
+		assert.Len(t, result.files, 12)
+		assert.True(t, result.maxActive >= 1 && result.maxActive <= int32(concurrency), "%d jobs ran at the same time with concurrency %d", result.maxActive, concurrency)
+	}
+}
+
+func TestRunJobsErrors(t *testing.T) {
+	result := runTestJobs(4, 10, 9, 0, 5)
+	if assert.IsType(t, GenerationErrors{}, result.err) {
+		errs := result.err.(GenerationErrors)
+		if assert.Len(t, errs, 3) {
+			// the errors are in the order of the jobs, not in the order the jobs failed
+			assert.Equal(t, "job00", errs[0].Name)
+			assert.Equal(t, "job05", errs[1].Name)
+			assert.Equal(t, "job09", errs[2].Name)
+			assert.EqualError(t, errs[1].Err, "failed job05")
+		}
+		assert.EqualError(t, result.err, "job00: failed job00\njob05: failed job05\njob09: failed job09")
+	}
+
+	var failed []string
+	for _, d := range result.diags {
+		if d.Level == DiagnosticError {
+			failed = append(failed, d.Target)
+		}
+	}
+	assert.Equal(t, []string{"job00", "job05", "job09"}, failed)
+
+	// the jobs that didn't fail still wrote their files
+	assert.Len(t, result.files, 7)
+	assert.NoError(t, runTestJobs(4, 10).err)
+}
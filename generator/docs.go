@@ -0,0 +1,700 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/go-swagger/go-swagger/swag"
+)
+
+// the formats the reference documentation can be rendered in
+const (
+	// DocsMarkdown renders an index page, a page per tag and a page per model in markdown
+	DocsMarkdown = "markdown"
+	// DocsHTML renders a single self-contained html page that doesn't load any external resources
+	DocsHTML = "html"
+)
+
+var docTemplates = map[string]*template.Template{}
+
+func init() {
+	for _, name := range []string{"index", "tag", "model", "html"} {
+		bd, _ := Asset("templates/docs/" + name + ".gotmpl")
+		docTemplates[name] = template.Must(template.New(name).Parse(string(bd)))
+	}
+}
+
+// GenerateDocs renders the reference documentation for a spec in the requested formats.
+// The templates can be overridden with files of the same name in the docs folder of the TemplateDir option.
+func GenerateDocs(name string, formats []string, opts GenOpts) error {
+	_, specDoc, err := loadSpec(opts)
+	if err != nil {
+		return err
+	}
+	if len(formats) == 0 {
+		formats = []string{DocsMarkdown, DocsHTML}
+	}
+	for _, f := range formats {
+		if f != DocsMarkdown && f != DocsHTML {
+			return fmt.Errorf("unknown docs format %q, expected %s or %s", f, DocsMarkdown, DocsHTML)
+		}
+	}
+
+	if name == "" {
+		if specDoc.Spec().Info != nil && specDoc.Spec().Info.Title != "" {
+			name = specDoc.Spec().Info.Title
+		} else {
+			name = "swagger"
+		}
+	}
+
+	generator := docsGenerator{
+		Name:        name,
+		SpecDoc:     specDoc,
+		Target:      opts.Target,
+		TemplateDir: opts.TemplateDir,
+		Formats:     formats,
+		DumpData:    opts.DumpData,
+		out:         newOutput(opts),
+	}
+	return generator.Generate()
+}
+
+type docsGenerator struct {
+	Name        string
+	SpecDoc     *spec.Document
+	Target      string
+	TemplateDir string
+	Formats     []string
+	DumpData    bool
+	out         *output
+}
+
+func (d *docsGenerator) Generate() error {
+	docs := d.makeCodegenDocs()
+
+	if d.DumpData {
+		bb, _ := json.MarshalIndent(swag.ToDynamicJSON(docs), "", "  ")
+		fmt.Fprintln(os.Stdout, string(bb))
+		return nil
+	}
+
+	for _, format := range d.Formats {
+		if format == DocsHTML {
+			if err := d.render("html", "index.html", docs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := d.render("index", "README.md", docs); err != nil {
+			return err
+		}
+		for _, tag := range docs.Tags {
+			if err := d.render("tag", tag.Anchor+".md", tag); err != nil {
+				return err
+			}
+		}
+		for _, model := range docs.Models {
+			if err := d.render("model", model.Anchor+".md", model); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// template returns the override from the template dir when there is one
+func (d *docsGenerator) template(name string) (*template.Template, error) {
+	if d.TemplateDir != "" {
+		pth := filepath.Join(d.TemplateDir, "docs", name+".gotmpl")
+		if b, err := ioutil.ReadFile(pth); err == nil {
+			return template.New(name).Parse(string(b))
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return docTemplates[name], nil
+}
+
+func (d *docsGenerator) render(tmpl, fileName string, data interface{}) error {
+	t, err := d.template(tmpl)
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := t.Execute(buf, data); err != nil {
+		return err
+	}
+	d.out.Info("rendered "+tmpl+" docs template", fileName)
+	return d.out.writeFile(filepath.Join(d.Target, "docs"), fileName, buf.Bytes())
+}
+
+type genDocs struct {
+	Title               string
+	Version             string
+	Description         string
+	Host                string
+	BasePath            string
+	Schemes             []string
+	Consumes            []string
+	Produces            []string
+	Tags                []genDocTag
+	Models              []genDocModel
+	SecurityDefinitions []genDocSecurityScheme
+}
+
+type genDocTag struct {
+	Name        string
+	Anchor      string
+	Description string
+	Operations  []genDocOperation
+}
+
+type genDocOperation struct {
+	ID          string
+	Anchor      string
+	TagAnchor   string
+	Tags        []string
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Deprecated  bool
+	Consumes    []string
+	Produces    []string
+	Params      []genDocParam
+	Body        *genDocParam
+	Example     string
+	Responses   []genDocResponse
+	Security    []genDocSecurity
+}
+
+type genDocParam struct {
+	Name        string
+	In          string
+	Type        genDocType
+	Required    bool
+	Description string
+	Default     string
+	Enum        string
+}
+
+type genDocResponse struct {
+	Code        string
+	Description string
+	Type        *genDocType
+	Example     string
+	Headers     []genDocParam
+}
+
+// genDocType describes the type of a value, Model is set when the type links to a model page
+type genDocType struct {
+	Container string
+	Name      string
+	Model     string
+	Anchor    string
+}
+
+type genDocSecurity struct {
+	Name   string
+	Anchor string
+	Scopes []string
+}
+
+type genDocSecurityScheme struct {
+	Name        string
+	Anchor      string
+	Type        string
+	Description string
+	In          string
+	ParamName   string
+	Flow        string
+	Scopes      []string
+}
+
+type genDocModel struct {
+	Name        string
+	Anchor      string
+	Summary     string
+	Description string
+	Type        genDocType
+	Properties  []genDocProperty
+	Example     string
+	UsedBy      []genDocOperation
+}
+
+type genDocProperty struct {
+	Name        string
+	Type        genDocType
+	Required    bool
+	ReadOnly    bool
+	Description string
+	Enum        string
+}
+
+func modelAnchor(name string) string {
+	return "model-" + swag.ToFileName(name)
+}
+
+func tagAnchor(name string) string {
+	return "tag-" + swag.ToFileName(name)
+}
+
+func securityAnchor(name string) string {
+	return "security-" + swag.ToFileName(name)
+}
+
+func (d *docsGenerator) makeCodegenDocs() genDocs {
+	sw := d.SpecDoc.Spec()
+	docs := genDocs{
+		Title:    d.Name,
+		Host:     sw.Host,
+		BasePath: sw.BasePath,
+		Schemes:  sw.Schemes,
+		Consumes: sw.Consumes,
+		Produces: sw.Produces,
+	}
+	if sw.Info != nil {
+		docs.Version = sw.Info.Version
+		docs.Description = sw.Info.Description
+	}
+
+	// tags are listed in the order of the spec, undeclared tags follow in alphabetical order
+	// and operations without a tag end up in the default group at the end
+	var tagNames []string
+	tags := make(map[string]*genDocTag)
+	for _, t := range sw.Tags {
+		tagNames = append(tagNames, t.Name)
+		tags[t.Name] = &genDocTag{Name: t.Name, Anchor: tagAnchor(t.Name), Description: t.Description}
+	}
+
+	var undeclared []string
+	untagged := genDocTag{Name: "default", Anchor: tagAnchor("default")}
+	usedBy := make(map[string][]genDocOperation)
+	for _, op := range d.makeDocOperations() {
+		if len(op.Tags) == 0 {
+			op.TagAnchor = untagged.Anchor
+			untagged.Operations = append(untagged.Operations, op)
+		} else {
+			op.TagAnchor = tagAnchor(op.Tags[0])
+		}
+		for _, t := range op.Tags {
+			if _, ok := tags[t]; !ok {
+				undeclared = append(undeclared, t)
+				tags[t] = &genDocTag{Name: t, Anchor: tagAnchor(t)}
+			}
+			tags[t].Operations = append(tags[t].Operations, op)
+		}
+		for _, m := range op.models() {
+			usedBy[m] = append(usedBy[m], op)
+		}
+	}
+	sort.Strings(undeclared)
+	for _, t := range append(tagNames, undeclared...) {
+		if len(tags[t].Operations) > 0 {
+			docs.Tags = append(docs.Tags, *tags[t])
+		}
+	}
+	if len(untagged.Operations) > 0 {
+		docs.Tags = append(docs.Tags, untagged)
+	}
+
+	var modelNames []string
+	for k := range sw.Definitions {
+		modelNames = append(modelNames, k)
+	}
+	sort.Strings(modelNames)
+	for _, k := range modelNames {
+		model := d.makeDocModel(k, sw.Definitions[k])
+		model.UsedBy = usedBy[k]
+		docs.Models = append(docs.Models, model)
+	}
+
+	var schemeNames []string
+	for k := range sw.SecurityDefinitions {
+		schemeNames = append(schemeNames, k)
+	}
+	sort.Strings(schemeNames)
+	for _, k := range schemeNames {
+		scheme := sw.SecurityDefinitions[k]
+		var scopes []string
+		for s := range scheme.Scopes {
+			scopes = append(scopes, s)
+		}
+		sort.Strings(scopes)
+		docs.SecurityDefinitions = append(docs.SecurityDefinitions, genDocSecurityScheme{
+			Name:        k,
+			Anchor:      securityAnchor(k),
+			Type:        scheme.Type,
+			Description: scheme.Description,
+			In:          scheme.In,
+			ParamName:   scheme.Name,
+			Flow:        scheme.Flow,
+			Scopes:      scopes,
+		})
+	}
+	return docs
+}
+
+// makeDocOperations returns all the operations of the spec sorted by path and method
+func (d *docsGenerator) makeDocOperations() []genDocOperation {
+	var result []genDocOperation
+	for method, paths := range d.SpecDoc.Operations() {
+		for path, op := range paths {
+			result = append(result, d.makeDocOperation(method, path, op))
+		}
+	}
+	sort.Sort(genDocOperationSlice(result))
+	return result
+}
+
+func (d *docsGenerator) makeDocOperation(method, path string, op *spec.Operation) genDocOperation {
+	id := op.ID
+	if id == "" {
+		id = strings.ToLower(method) + " " + path
+	}
+	res := genDocOperation{
+		ID:          id,
+		Anchor:      "operation-" + swag.ToFileName(id),
+		Method:      strings.ToUpper(method),
+		Path:        path,
+		Summary:     docCell(op.Summary),
+		Description: op.Description,
+		Deprecated:  op.Deprecated,
+		Tags:        op.Tags,
+		Consumes:    d.SpecDoc.ConsumesFor(op),
+		Produces:    d.SpecDoc.ProducesFor(op),
+	}
+	sort.Strings(res.Consumes)
+	sort.Strings(res.Produces)
+
+	var params []spec.Parameter
+	if pi, ok := d.SpecDoc.AllPaths()[path]; ok {
+		params = append(params, pi.Parameters...)
+	}
+	params = append(params, op.Parameters...)
+	seen := make(map[string]int)
+	for _, p := range params {
+		p = d.resolveParam(p)
+		param := genDocParam{
+			Name:        p.Name,
+			In:          p.In,
+			Required:    p.Required,
+			Description: docCell(p.Description),
+			Default:     docValue(p.Default),
+			Enum:        docEnum(p.Enum),
+		}
+		if p.In == "body" {
+			param.Type = d.schemaType(p.Schema)
+			res.Body = &param
+			res.Example = docExample(d.exampleFor(p.Schema, nil))
+			continue
+		}
+		param.Type = simpleType(p.Type, p.Format, p.Items)
+		// operation parameters override the parameters of the path
+		if idx, ok := seen[p.In+"."+p.Name]; ok {
+			res.Params[idx] = param
+			continue
+		}
+		seen[p.In+"."+p.Name] = len(res.Params)
+		res.Params = append(res.Params, param)
+	}
+
+	if op.Responses != nil {
+		var codes []int
+		for code := range op.Responses.StatusCodeResponses {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			res.Responses = append(res.Responses, d.makeDocResponse(strconv.Itoa(code), op.Responses.StatusCodeResponses[code]))
+		}
+		if op.Responses.Default != nil {
+			res.Responses = append(res.Responses, d.makeDocResponse("default", *op.Responses.Default))
+		}
+	}
+
+	for _, req := range d.SpecDoc.SecurityRequirementsFor(op) {
+		res.Security = append(res.Security, genDocSecurity{Name: req.Name, Anchor: securityAnchor(req.Name), Scopes: req.Scopes})
+	}
+	return res
+}
+
+func (d *docsGenerator) makeDocResponse(code string, resp spec.Response) genDocResponse {
+	if ref := resp.Ref.GetURL(); ref != nil {
+		if r, ok := d.SpecDoc.Spec().Responses[filepath.Base(ref.Fragment)]; ok {
+			resp = r
+		}
+	}
+	res := genDocResponse{Code: code, Description: resp.Description}
+	if resp.Schema != nil {
+		tpe := d.schemaType(resp.Schema)
+		res.Type = &tpe
+		if ex, ok := resp.Examples.(map[string]interface{}); ok && ex["application/json"] != nil {
+			res.Example = docExample(ex["application/json"])
+		} else {
+			res.Example = docExample(d.exampleFor(resp.Schema, nil))
+		}
+	}
+	var headers []string
+	for k := range resp.Headers {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+	for _, k := range headers {
+		h := resp.Headers[k]
+		res.Headers = append(res.Headers, genDocParam{
+			Name:        k,
+			In:          "header",
+			Type:        simpleType(h.Type, h.Format, h.Items),
+			Description: docCell(h.Description),
+		})
+	}
+	return res
+}
+
+func (d *docsGenerator) resolveParam(p spec.Parameter) spec.Parameter {
+	if ref := p.Ref.GetURL(); ref != nil {
+		if rp, ok := d.SpecDoc.Spec().Parameters[filepath.Base(ref.Fragment)]; ok {
+			return rp
+		}
+	}
+	return p
+}
+
+func (d *docsGenerator) makeDocModel(name string, schema spec.Schema) genDocModel {
+	res := genDocModel{
+		Name:        name,
+		Anchor:      modelAnchor(name),
+		Summary:     docCell(schema.Description),
+		Description: schema.Description,
+		Type:        d.schemaType(&schema),
+		Example:     docExample(d.exampleFor(&schema, nil)),
+	}
+
+	props := make(map[string]spec.Schema)
+	required := make(map[string]bool)
+	for _, s := range append([]spec.Schema{schema}, schema.AllOf...) {
+		if ref := s.Ref.GetURL(); ref != nil {
+			s = d.SpecDoc.Spec().Definitions[filepath.Base(ref.Fragment)]
+		}
+		for k, v := range s.Properties {
+			props[k] = v
+		}
+		for _, r := range s.Required {
+			required[r] = true
+		}
+	}
+	var names []string
+	for k := range props {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		p := props[k]
+		res.Properties = append(res.Properties, genDocProperty{
+			Name:        k,
+			Type:        d.schemaType(&p),
+			Required:    required[k],
+			ReadOnly:    p.ReadOnly,
+			Description: docCell(p.Description),
+			Enum:        docEnum(p.Enum),
+		})
+	}
+	return res
+}
+
+// schemaType describes the type of a schema, following $ref to the model it points to
+func (d *docsGenerator) schemaType(schema *spec.Schema) genDocType {
+	if schema == nil {
+		return genDocType{Name: "any"}
+	}
+	if ref := schema.Ref.GetURL(); ref != nil {
+		nm := filepath.Base(ref.Fragment)
+		return genDocType{Name: nm, Model: nm, Anchor: modelAnchor(nm)}
+	}
+	if schema.Type.Contains("array") || schema.Items != nil {
+		var item genDocType
+		if schema.Items != nil && schema.Items.Schema != nil {
+			item = d.schemaType(schema.Items.Schema)
+		} else {
+			item = genDocType{Name: "any"}
+		}
+		item.Container = "array of " + item.Container
+		return item
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		item := d.schemaType(schema.AdditionalProperties.Schema)
+		item.Container = "map of " + item.Container
+		return item
+	}
+	if len(schema.Type) == 0 || schema.Type.Contains("object") {
+		return genDocType{Name: "object"}
+	}
+	tn := schema.Type[0]
+	if schema.Format != "" {
+		tn += " (" + schema.Format + ")"
+	}
+	return genDocType{Name: tn}
+}
+
+func simpleType(tpe, format string, items *spec.Items) genDocType {
+	if tpe == "array" && items != nil {
+		item := simpleType(items.Type, items.Format, items.Items)
+		item.Container = "array of " + item.Container
+		return item
+	}
+	if format != "" {
+		return genDocType{Name: tpe + " (" + format + ")"}
+	}
+	return genDocType{Name: tpe}
+}
+
+// exampleFor uses the example of a schema, or builds one from its properties when it doesn't have one
+func (d *docsGenerator) exampleFor(schema *spec.Schema, visited map[string]bool) interface{} {
+	if schema == nil {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if ref := schema.Ref.GetURL(); ref != nil {
+		nm := filepath.Base(ref.Fragment)
+		if visited[nm] {
+			return nil
+		}
+		seen := map[string]bool{nm: true}
+		for k := range visited {
+			seen[k] = true
+		}
+		def := d.SpecDoc.Spec().Definitions[nm]
+		return d.exampleFor(&def, seen)
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	switch {
+	case schema.Type.Contains("array") || schema.Items != nil:
+		if schema.Items != nil && schema.Items.Schema != nil {
+			if ex := d.exampleFor(schema.Items.Schema, visited); ex != nil {
+				return []interface{}{ex}
+			}
+		}
+		return []interface{}{}
+	case schema.Type.Contains("string"):
+		switch schema.Format {
+		case "date":
+			return "2015-01-01"
+		case "date-time":
+			return "2015-01-01T00:00:00.000Z"
+		}
+		return "string"
+	case schema.Type.Contains("integer"):
+		return 0
+	case schema.Type.Contains("number"):
+		return 0.0
+	case schema.Type.Contains("boolean"):
+		return true
+	}
+
+	res := make(map[string]interface{})
+	for _, s := range append([]spec.Schema{*schema}, schema.AllOf...) {
+		if ref := s.Ref.GetURL(); ref != nil {
+			if ex, ok := d.exampleFor(&s, visited).(map[string]interface{}); ok {
+				for k, v := range ex {
+					res[k] = v
+				}
+			}
+			continue
+		}
+		for k, v := range s.Properties {
+			if ex := d.exampleFor(&v, visited); ex != nil {
+				res[k] = ex
+			}
+		}
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		if ex := d.exampleFor(schema.AdditionalProperties.Schema, visited); ex != nil {
+			res["key"] = ex
+		}
+	}
+	return res
+}
+
+// models returns the names of the models an operation refers to directly
+func (o genDocOperation) models() []string {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(t *genDocType) {
+		if t != nil && t.Model != "" && !seen[t.Model] {
+			seen[t.Model] = true
+			result = append(result, t.Model)
+		}
+	}
+	if o.Body != nil {
+		add(&o.Body.Type)
+	}
+	for _, r := range o.Responses {
+		add(r.Type)
+	}
+	return result
+}
+
+// docCell puts a text on a single line, so it can be used in a table
+func docCell(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func docExample(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	b, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func docValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+func docEnum(values []interface{}) string {
+	var result []string
+	for _, v := range values {
+		result = append(result, docValue(v))
+	}
+	return strings.Join(result, ", ")
+}
+
+type genDocOperationSlice []genDocOperation
+
+func (g genDocOperationSlice) Len() int      { return len(g) }
+func (g genDocOperationSlice) Swap(i, j int) { g[i], g[j] = g[j], g[i] }
+func (g genDocOperationSlice) Less(i, j int) bool {
+	if g[i].Path == g[j].Path {
+		return g[i].Method < g[j].Method
+	}
+	return g[i].Path < g[j].Path
+}
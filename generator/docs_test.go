@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func renderDocs(t *testing.T, format string) map[string]string {
+	opts := testGenOpts("../fixtures/codegen/docs-escaping.yml", "api")
+	fs := NewMemFileSystem()
+	opts.FileSystem = fs
+	opts.Diagnostics = func(Diagnostic) {}
+	if !assert.NoError(t, GenerateDocs("", []string{format}, opts)) {
+		return nil
+	}
+	result := make(map[string]string)
+	for _, f := range fs.Files() {
+		result[f.Path] = string(f.Content)
+	}
+	return result
+}
+
+func TestDocsHTMLEscaping(t *testing.T) {
+	files := renderDocs(t, DocsHTML)
+	page, ok := files["api/docs/index.html"]
+	if !assert.True(t, ok, "files: %v", files) {
+		return
+	}
+
+	// the only markup on the page comes from the template
+	assert.NotContains(t, page, "<script>")
+	assert.NotContains(t, page, "<b>")
+	assert.NotContains(t, page, "<pet>")
+	assert.NotContains(t, page, `"><`)
+	assert.Contains(t, page, "<title>Pets &lt;script&gt;alert(&#34;title&#34;)&lt;/script&gt;</title>")
+	assert.Contains(t, page, "Pets &amp; &lt;b&gt;owners&lt;/b&gt;")
+	assert.Contains(t, page, "the &lt;name&gt; &amp; nickname")
+	assert.Contains(t, page, `id="security-key_script_alert_scheme_script"`)
+	assert.Contains(t, page, "X-&lt;Token&gt;")
+}
+
+func TestDocsMarkdown(t *testing.T) {
+	files := renderDocs(t, DocsMarkdown)
+	var names []string
+	for k := range files {
+		names = append(names, k)
+	}
+	assert.Contains(t, names, "api/docs/README.md")
+	for name, content := range files {
+		assert.True(t, strings.HasPrefix(name, "api/docs/"), name)
+		assert.NotEmpty(t, content, name)
+	}
+	// the security schemes are linked with the same anchor as the html page uses
+	assert.Contains(t, files["api/docs/tag-pets_b.md"], "(README.md#security-key_script_alert_scheme_script)")
+	assert.Contains(t, files["api/docs/README.md"], `<a name="security-key_script_alert_scheme_script"></a>`)
+}
@@ -0,0 +1,367 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/go-swagger/go-swagger/swag"
+)
+
+var fakeTemplate *template.Template
+
+func init() {
+	bf, _ := Asset("templates/server/fake.gotmpl")
+	fakeTemplate = template.Must(template.New("fake").Parse(string(bf)))
+}
+
+// the kinds of handlers the fake generates for an operation
+const (
+	fakeCreate = "create"
+	fakeGet    = "get"
+	fakeList   = "list"
+	fakeUpdate = "update"
+	fakeDelete = "delete"
+	fakeCanned = "canned"
+)
+
+// GenerateFake generates an in-memory implementation of the handlers of an API.
+// Conventional REST operations get CRUD semantics backed by a store per definition,
+// all the other operations return a canned response.
+func GenerateFake(name string, modelNames, operationIDs []string, opts GenOpts) error {
+	generator, err := newAppGenerator(name, modelNames, operationIDs, false, opts)
+	if err != nil {
+		return err
+	}
+	return generator.GenerateFake()
+}
+
+// GenerateFake renders the fake for the API in the fake package of the server package
+func (a *appGenerator) GenerateFake() error {
+	fake, err := a.makeCodegenFake()
+	if err != nil {
+		return err
+	}
+
+	if a.DumpData {
+		bb, _ := json.MarshalIndent(swag.ToDynamicJSON(fake), "", "  ")
+		fmt.Fprintln(os.Stdout, string(bb))
+		return nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := fakeTemplate.Execute(buf, fake); err != nil {
+		return err
+	}
+	a.out.Info("rendered fake template", "fake."+fake.AppName)
+	return a.out.writeToFile(filepath.Join(a.Target, a.ServerPackage, "fake"), fake.AppName+"Fake", buf.Bytes())
+}
+
+type genFake struct {
+	genApp
+	APIPackage string
+	Stores     []genFakeStore
+	Handlers   []genFakeHandler
+}
+
+// genFakeStore is the store for the values of a single definition
+type genFakeStore struct {
+	ClassName      string
+	HumanClassName string
+	Model          string
+}
+
+// genFakeHandler is the in-memory implementation of a single operation
+type genFakeHandler struct {
+	genOperation
+	Kind        string
+	Store       string
+	Model       string
+	Body        string
+	Parent      []string
+	Item        []string
+	ItemParam   string
+	IDField     string
+	IDType      string
+	Convert     bool
+	ReturnsIt   bool
+	ReturnsBody bool
+}
+
+// fakeRoute is the method and path an operation is served on
+type fakeRoute struct {
+	Method string
+	Path   string
+}
+
+func (a *appGenerator) makeCodegenFake() (genFake, error) {
+	app, err := a.makeCodegenApp()
+	if err != nil {
+		return genFake{}, err
+	}
+	baseImp, err := baseImport(a.Target)
+	if err != nil {
+		return genFake{}, err
+	}
+	apiImport := filepath.ToSlash(filepath.Join(baseImp, a.ServerPackage, a.APIPackage))
+	app.DefaultImports = append(app.DefaultImports, apiImport)
+	for _, op := range app.Operations {
+		for k, v := range op.Imports {
+			if app.Imports == nil {
+				app.Imports = make(map[string]string)
+			}
+			app.Imports[k] = v
+		}
+	}
+
+	routes := fakeRoutes(a.SpecDoc.Spec())
+
+	// the collection path of a resource decides the store, so all the operations on
+	// /pets and /pets/{id} end up using the same store.
+	// The store holds the model the resource is read as, the body of a create or update
+	// only decides the model when no operation reads the resource.
+	stores := make(map[string]string)
+	written := make(map[string]string)
+	itemParams := make(map[string]string)
+	for _, op := range app.Operations {
+		route, ok := routes[op.Name]
+		if !ok {
+			continue
+		}
+		coll, item := collectionPath(route.Path)
+		if item != "" {
+			itemParams[coll] = item
+		}
+		model := a.fakeResourceModel(op, route, item != "")
+		if model == "" {
+			continue
+		}
+		known := stores
+		if route.Method != "GET" {
+			known = written
+		}
+		if _, ok := known[coll]; !ok {
+			known[coll] = model
+		}
+	}
+	for coll, model := range written {
+		if _, ok := stores[coll]; !ok {
+			stores[coll] = model
+		}
+	}
+
+	seen := make(map[string]bool)
+	stored := make(map[string]bool)
+	var genStores []genFakeStore
+	var handlers []genFakeHandler
+	for _, op := range app.Operations {
+		// an operation with several tags only needs to be implemented once
+		if seen[op.ClassName] {
+			continue
+		}
+		seen[op.ClassName] = true
+
+		handler := genFakeHandler{genOperation: op, Kind: fakeCanned}
+		if handler.Package == "" {
+			handler.Package = a.Package
+		}
+		if route, ok := routes[op.Name]; ok {
+			coll, item := collectionPath(route.Path)
+			if model, ok := stores[coll]; ok {
+				a.classifyFakeHandler(&handler, route, model, item != "", itemParams[coll])
+			}
+		}
+		if handler.Kind != fakeCanned && !stored[handler.Model] {
+			stored[handler.Model] = true
+			genStores = append(genStores, genFakeStore{
+				ClassName:      handler.Store,
+				HumanClassName: swag.ToHumanNameLower(handler.Store),
+				Model:          handler.Model,
+			})
+		}
+		handlers = append(handlers, handler)
+	}
+	sort.Sort(genFakeStoreSlice(genStores))
+
+	return genFake{
+		genApp:     app,
+		APIPackage: a.Package,
+		Stores:     genStores,
+		Handlers:   handlers,
+	}, nil
+}
+
+// fakeResourceModel returns the model an operation reads or writes, when it follows a REST convention
+func (a *appGenerator) fakeResourceModel(op genOperation, route fakeRoute, isItem bool) string {
+	switch route.Method {
+	case "GET":
+		if isItem && op.ReturnsComplexObject && a.isModel(op.SuccessModel) {
+			return op.SuccessModel
+		}
+		if !isItem && op.ReturnsContainer && a.isModel(strings.TrimPrefix(op.SuccessModel, "[]")) {
+			return strings.TrimPrefix(op.SuccessModel, "[]")
+		}
+	case "POST":
+		if !isItem {
+			return a.fakeBodyModel(op)
+		}
+	case "PUT":
+		if isItem {
+			return a.fakeBodyModel(op)
+		}
+	}
+	return ""
+}
+
+func (a *appGenerator) classifyFakeHandler(handler *genFakeHandler, route fakeRoute, model string, isItem bool, itemParam string) {
+	keys := pathParamNames(route.Path)
+	body := a.fakeBodyModel(handler.genOperation)
+
+	switch {
+	case route.Method == "GET" && isItem && handler.SuccessModel == model:
+		handler.Kind = fakeGet
+	case route.Method == "GET" && !isItem && handler.SuccessModel == "[]"+model:
+		handler.Kind = fakeList
+	case route.Method == "POST" && !isItem && body != "":
+		handler.Kind = fakeCreate
+	case route.Method == "PUT" && isItem && body != "":
+		handler.Kind = fakeUpdate
+	case route.Method == "DELETE" && isItem:
+		handler.Kind = fakeDelete
+	default:
+		return
+	}
+
+	handler.Model = model
+	handler.Store = strings.TrimPrefix(model, a.ModelsPackage+".")
+	handler.ReturnsIt = handler.SuccessModel == model
+	if p, ok := bodyParam(handler.genOperation); ok {
+		handler.Body = p.PropertyName
+		// a body like NewPet gets stored as the Pet the resource is read as
+		handler.Convert = body != "" && body != model
+		handler.ReturnsBody = !handler.ReturnsIt && handler.SuccessModel == body
+	}
+	if isItem {
+		handler.Item = keys
+		handler.Parent = keys[:len(keys)-1]
+		handler.ItemParam = keys[len(keys)-1]
+	} else {
+		handler.Parent = keys
+	}
+
+	switch handler.Kind {
+	case fakeCreate:
+		if itemParam != "" {
+			handler.IDField, handler.IDType = a.fakeIDField(model, itemParam)
+		}
+	case fakeUpdate:
+		// the value stored for an update takes its identity from the path
+		field, tpe := a.fakeIDField(model, itemParam)
+		for _, p := range handler.PathParams {
+			if p.PropertyName == handler.ItemParam && p.Type == tpe {
+				handler.IDField, handler.IDType = field, tpe
+			}
+		}
+	}
+}
+
+// fakeBodyModel returns the model of the body parameter of an operation
+func (a *appGenerator) fakeBodyModel(op genOperation) string {
+	if p, ok := bodyParam(op); ok && a.isModel(p.Type) {
+		return p.Type
+	}
+	return ""
+}
+
+func bodyParam(op genOperation) (genParameter, bool) {
+	for _, p := range op.Params {
+		if p.IsBodyParam {
+			return p, true
+		}
+	}
+	return genParameter{}, false
+}
+
+func (a *appGenerator) isModel(tpe string) bool {
+	return a.ModelsPackage != "" && strings.HasPrefix(tpe, a.ModelsPackage+".")
+}
+
+// fakeIDField finds the property of the model that holds the value of the item path parameter,
+// only string and integer properties can be filled in by the fake when a value gets created
+func (a *appGenerator) fakeIDField(model, param string) (string, string) {
+	name := strings.TrimPrefix(model, a.ModelsPackage+".")
+	for k, schema := range a.SpecDoc.Spec().Definitions {
+		if swag.ToGoName(k) != name {
+			continue
+		}
+		prop, ok := schema.Properties[param]
+		if !ok {
+			return "", ""
+		}
+		tpe := a.Types.typeForSchema(&prop, a.ModelsPackage)
+		if tpe == "string" || strings.HasPrefix(tpe, "int") || strings.HasPrefix(tpe, "uint") {
+			return swag.ToGoName(param), tpe
+		}
+	}
+	return "", ""
+}
+
+// fakeRoutes maps the operations of a spec to the method and path they are served on, by codegen name
+func fakeRoutes(sw *spec.Swagger) map[string]fakeRoute {
+	routes := make(map[string]fakeRoute)
+	if sw.Paths == nil {
+		return routes
+	}
+	for path, item := range sw.Paths.Paths {
+		ops := map[string]*spec.Operation{
+			"GET":     item.Get,
+			"PUT":     item.Put,
+			"POST":    item.Post,
+			"DELETE":  item.Delete,
+			"OPTIONS": item.Options,
+			"HEAD":    item.Head,
+			"PATCH":   item.Patch,
+		}
+		for method, op := range ops {
+			if op != nil && op.ID != "" {
+				routes[swag.ToJSONName(op.ID)] = fakeRoute{Method: method, Path: path}
+			}
+		}
+	}
+	return routes
+}
+
+// collectionPath splits a path like /pets/{id} in the collection path /pets and the item parameter id.
+// When the path doesn't end in a parameter it is a collection path itself.
+func collectionPath(path string) (string, string) {
+	path = strings.TrimSuffix(path, "/")
+	idx := strings.LastIndex(path, "/")
+	last := path[idx+1:]
+	if strings.HasPrefix(last, "{") && strings.HasSuffix(last, "}") {
+		return path[:idx], last[1 : len(last)-1]
+	}
+	return path, ""
+}
+
+// pathParamNames returns the go names of the path parameters in the order they appear in the path
+func pathParamNames(path string) []string {
+	var result []string
+	for _, part := range strings.Split(path, "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			result = append(result, swag.ToGoName(part[1:len(part)-1]))
+		}
+	}
+	return result
+}
+
+type genFakeStoreSlice []genFakeStore
+
+func (g genFakeStoreSlice) Len() int           { return len(g) }
+func (g genFakeStoreSlice) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g genFakeStoreSlice) Less(i, j int) bool { return g[i].ClassName < g[j].ClassName }
@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeStoresReadModel(t *testing.T) {
+	withGopath(t, func(target string) {
+		opts := testGenOpts("../fixtures/petstores/petstore-expanded.json", target)
+		fs := NewMemFileSystem()
+		opts.FileSystem = fs
+		opts.Diagnostics = func(Diagnostic) {}
+
+		generator, err := newAppGenerator("", nil, nil, false, opts)
+		if !assert.NoError(t, err) {
+			return
+		}
+		fake, err := generator.makeCodegenFake()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// the pets get read as a Pet, so that's what gets stored even though they're created from a NewPet
+		if assert.Len(t, fake.Stores, 1) {
+			assert.Equal(t, "models.Pet", fake.Stores[0].Model)
+		}
+		kinds := make(map[string]genFakeHandler)
+		for _, h := range fake.Handlers {
+			kinds[h.ClassName] = h
+		}
+		assert.Equal(t, fakeGet, kinds["FindPetByID"].Kind)
+		assert.Equal(t, fakeList, kinds["FindPets"].Kind)
+		assert.Equal(t, fakeDelete, kinds["DeletePet"].Kind)
+
+		add := kinds["AddPet"]
+		assert.Equal(t, fakeCreate, add.Kind)
+		assert.Equal(t, "models.Pet", add.Model)
+		assert.True(t, add.Convert)
+		assert.True(t, add.ReturnsIt)
+		assert.Equal(t, "ID", add.IDField)
+
+		if !assert.NoError(t, generator.GenerateFake()) {
+			return
+		}
+		files := fs.Files()
+		if assert.Len(t, files, 1) {
+			assert.Equal(t, filepath.Join(target, "restapi", "fake", "swagger_petstore_fake.go"), files[0].Path)
+			code := string(files[0].Content)
+			addPet := code[strings.Index(code, "func (f *SwaggerPetstore) AddPet("):]
+			addPet = addPet[:strings.Index(addPet, "\n}\n")]
+			assert.Contains(t, addPet, "var value models.Pet")
+			assert.Contains(t, addPet, "inmem.Convert(params.Pet, &value)")
+			assert.Contains(t, addPet, "f.Pet.Create(key, value)")
+			assert.Contains(t, addPet, "return &value, nil")
+		}
+	})
+}
+
+// fakeRoundTrip serves the generated fake and prints the status and the body of the responses to a series of requests
+const fakeRoundTrip = `package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-swagger/go-swagger/generator/{{dir}}/restapi/fake"
+)
+
+func main() {
+	server, err := fake.New().NewServer()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer server.Close()
+
+	for _, req := range [][]string{
+		{"POST", "/api/pets", ` + "`" + `{"name":"fido","tag":"dog"}` + "`" + `},
+		{"POST", "/api/pets", ` + "`" + `{"name":"felix"}` + "`" + `},
+		{"GET", "/api/pets/1", ""},
+		{"GET", "/api/pets", ""},
+		{"PUT", "/api/pets/1", ` + "`" + `{"name":"rex"}` + "`" + `},
+		{"GET", "/api/pets/1", ""},
+		{"DELETE", "/api/pets/1", ""},
+		{"GET", "/api/pets/1", ""},
+		{"GET", "/api/pets", ""},
+		{"POST", "/api/pets", ` + "`" + `{"name":"tom","owner":"alice"}` + "`" + `},
+	} {
+		request, _ := http.NewRequest(req[0], server.URL+req[1], strings.NewReader(req[2]))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept", "application/json")
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		fmt.Println(strings.TrimSpace(fmt.Sprint(req[0], " ", req[1], " ", response.StatusCode, " ", strings.TrimSpace(string(body)))))
+	}
+}
+`
+
+func TestFakeRoundTrip(t *testing.T) {
+	opts := testGenOpts("../fixtures/codegen/fake.yml", "")
+	generateFake := func(opts GenOpts) error {
+		return GenerateFake("", nil, nil, opts)
+	}
+	generateServer(t, opts, generateFake, func(dir string) {
+		out, err := runGenerated(dir, fakeRoundTrip)
+		if !assert.NoError(t, err, out) {
+			return
+		}
+		assert.Equal(t, []string{
+			`POST /api/pets 200 {"id":1,"name":"fido","tag":"dog"}`,
+			`POST /api/pets 200 {"id":2,"name":"felix","tag":""}`,
+			`GET /api/pets/1 200 {"id":1,"name":"fido","tag":"dog"}`,
+			`GET /api/pets 200 [{"id":1,"name":"fido","tag":"dog"},{"id":2,"name":"felix","tag":""}]`,
+			`PUT /api/pets/1 200 {"id":1,"name":"rex","tag":""}`,
+			`GET /api/pets/1 200 {"id":1,"name":"rex","tag":""}`,
+			`DELETE /api/pets/1 204`,
+			`GET /api/pets/1 404 {"code":404,"message":"Not found"}`,
+			`GET /api/pets 200 [{"id":2,"name":"felix","tag":""}]`,
+			// the fake has nowhere to keep the owner of a pet, so it doesn't pretend it stored it
+			`POST /api/pets 501 {"code":501,"message":"models.Pet has no field for owner"}`,
+		}, strings.Split(strings.TrimSpace(out), "\n"))
+	})
+}
@@ -0,0 +1,292 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/go-swagger/go-swagger/strfmt"
+	"github.com/go-swagger/go-swagger/swag"
+	"github.com/go-swagger/go-swagger/validate"
+)
+
+const jsonSchemaDraft04URI = "http://json-schema.org/draft-04/schema#"
+
+// the keywords swagger adds to json schema, these are removed from the exported schemas
+var swaggerSchemaKeywords = []string{"discriminator", "readOnly", "xml", "externalDocs", "example"}
+
+// GenerateJSONSchema writes a json schema draft 4 document for every definition of a spec.
+// References to other definitions become references to their files, or when inline is true
+// the definitions are copied into the definitions of the document so it stands on its own.
+func GenerateJSONSchema(modelNames []string, inline bool, opts GenOpts) error {
+	_, specDoc, err := loadSpec(opts)
+	if err != nil {
+		return err
+	}
+
+	generator := jsonSchemaGenerator{
+		SpecDoc:    specDoc,
+		ModelNames: modelNames,
+		Inline:     inline,
+		Target:     opts.Target,
+		DumpData:   opts.DumpData,
+		out:        newOutput(opts),
+		warned:     make(map[string]bool),
+	}
+	return generator.Generate()
+}
+
+type jsonSchemaGenerator struct {
+	SpecDoc    *spec.Document
+	ModelNames []string
+	Inline     bool
+	Target     string
+	DumpData   bool
+	out        *output
+	warned     map[string]bool
+}
+
+func (j *jsonSchemaGenerator) Generate() error {
+	metaSchema, err := spec.JSONSchemaDraft04()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for k := range j.SpecDoc.Spec().Definitions {
+		if j.includes(k) {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		doc, err := j.makeDocument(name)
+		if err != nil {
+			return err
+		}
+
+		if j.DumpData {
+			bb, _ := json.MarshalIndent(doc, "", "  ")
+			fmt.Fprintln(os.Stdout, string(bb))
+			continue
+		}
+
+		// the document is checked as it will be read back, so the meta schema sees plain json values
+		bb, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return err
+		}
+		var data interface{}
+		if err := json.Unmarshal(bb, &data); err != nil {
+			return err
+		}
+		if err := validate.AgainstSchema(metaSchema, data, strfmt.Default); err != nil {
+			return fmt.Errorf("the json schema for %s is not a valid draft 4 schema: %v", name, err)
+		}
+
+		j.out.Info("generated json schema", name)
+		if err := j.out.writeFile(filepath.Join(j.Target, "jsonschema"), jsonSchemaFileName(name), append(bb, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *jsonSchemaGenerator) includes(name string) bool {
+	if len(j.ModelNames) == 0 {
+		return true
+	}
+	for _, nm := range j.ModelNames {
+		if nm == name {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonSchemaFileName(name string) string {
+	return swag.ToFileName(name) + ".json"
+}
+
+// makeDocument creates the json schema document for a definition
+func (j *jsonSchemaGenerator) makeDocument(name string) (map[string]interface{}, error) {
+	doc, err := j.convert(name, j.SpecDoc.Spec().Definitions[name])
+	if err != nil {
+		return nil, err
+	}
+
+	if j.Inline {
+		// every definition that is referenced directly or indirectly ends up in the definitions
+		definitions := make(map[string]interface{})
+		pending := jsonSchemaRefs(doc)
+		for len(pending) > 0 {
+			ref := pending[0]
+			pending = pending[1:]
+			if _, done := definitions[ref]; done || ref == name {
+				continue
+			}
+			def, ok := j.SpecDoc.Spec().Definitions[ref]
+			if !ok {
+				return nil, fmt.Errorf("%s: can't resolve the reference to %s", name, ref)
+			}
+			converted, err := j.convert(ref, def)
+			if err != nil {
+				return nil, err
+			}
+			definitions[ref] = converted
+			pending = append(pending, jsonSchemaRefs(converted)...)
+		}
+		if len(definitions) > 0 {
+			doc["definitions"] = definitions
+		}
+		// the definition itself is the root of the document
+		jsonSchemaRewriteRef(doc, "#/definitions/"+name, "#")
+	}
+
+	doc["$schema"] = jsonSchemaDraft04URI
+	if _, ok := doc["title"]; !ok {
+		doc["title"] = name
+	}
+	return doc, nil
+}
+
+// convert turns a swagger schema into a plain json schema
+func (j *jsonSchemaGenerator) convert(name string, schema spec.Schema) (map[string]interface{}, error) {
+	bb, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(bb, &doc); err != nil {
+		return nil, err
+	}
+	return doc, j.cleanSchema(name, doc)
+}
+
+// cleanSchema strips the swagger keywords and rewrites the references of a schema and its sub schemas
+func (j *jsonSchemaGenerator) cleanSchema(path string, schema map[string]interface{}) error {
+	for k := range schema {
+		if strings.HasPrefix(strings.ToLower(k), "x-") {
+			delete(schema, k)
+		}
+	}
+	for _, k := range swaggerSchemaKeywords {
+		if _, ok := schema[k]; !ok {
+			continue
+		}
+		delete(schema, k)
+		// a definition gets converted again for every document that inlines it, it only needs to be reported once
+		if k == "discriminator" && !j.warned[path] {
+			j.warned[path] = true
+			j.out.Warn("json schema has no discriminator, the subtypes need to be selected with oneOf", path, nil)
+		}
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if strings.HasPrefix(ref, "#/definitions/") {
+			def := strings.TrimPrefix(ref, "#/definitions/")
+			if _, known := j.SpecDoc.Spec().Definitions[def]; !known {
+				return fmt.Errorf("%s: can't resolve the reference to %s", path, def)
+			}
+			if j.Inline {
+				schema["$ref"] = ref
+			} else {
+				schema["$ref"] = jsonSchemaFileName(def)
+			}
+		}
+	}
+
+	for _, k := range []string{"properties", "patternProperties", "definitions"} {
+		if props, ok := schema[k].(map[string]interface{}); ok {
+			for name, prop := range props {
+				if sub, ok := prop.(map[string]interface{}); ok {
+					if err := j.cleanSchema(path+"."+name, sub); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	for _, k := range []string{"items", "additionalItems", "additionalProperties", "not"} {
+		switch sub := schema[k].(type) {
+		case map[string]interface{}:
+			if err := j.cleanSchema(path, sub); err != nil {
+				return err
+			}
+		case []interface{}:
+			if err := j.cleanSchemas(path, sub); err != nil {
+				return err
+			}
+		}
+	}
+	for _, k := range []string{"allOf", "anyOf", "oneOf"} {
+		if subs, ok := schema[k].([]interface{}); ok {
+			if err := j.cleanSchemas(path, subs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (j *jsonSchemaGenerator) cleanSchemas(path string, schemas []interface{}) error {
+	for _, s := range schemas {
+		if sub, ok := s.(map[string]interface{}); ok {
+			if err := j.cleanSchema(path, sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonSchemaRefs returns the names of the definitions a converted schema refers to
+func jsonSchemaRefs(node interface{}) []string {
+	var result []string
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := n["$ref"].(string); ok && strings.HasPrefix(ref, "#/definitions/") {
+			result = append(result, strings.TrimPrefix(ref, "#/definitions/"))
+		}
+		var keys []string
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if k == "enum" || k == "default" {
+				continue
+			}
+			result = append(result, jsonSchemaRefs(n[k])...)
+		}
+	case []interface{}:
+		for _, v := range n {
+			result = append(result, jsonSchemaRefs(v)...)
+		}
+	}
+	return result
+}
+
+// jsonSchemaRewriteRef replaces the references to from with references to to
+func jsonSchemaRewriteRef(node interface{}, from, to string) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := n["$ref"].(string); ok && ref == from {
+			n["$ref"] = to
+		}
+		for k, v := range n {
+			if k != "enum" && k != "default" {
+				jsonSchemaRewriteRef(v, from, to)
+			}
+		}
+	case []interface{}:
+		for _, v := range n {
+			jsonSchemaRewriteRef(v, from, to)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/go-swagger/go-swagger/strfmt"
+	"github.com/go-swagger/go-swagger/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+var jsonSchemaFixtures = []string{
+	"../fixtures/codegen/jsonschema.yml",
+	"../fixtures/codegen/tasklist.basic.yml",
+}
+
+func renderJSONSchemas(t *testing.T, fixture string, inline bool) map[string]map[string]interface{} {
+	opts := testGenOpts(fixture, "api")
+	fs := NewMemFileSystem()
+	opts.FileSystem = fs
+	opts.Diagnostics = func(Diagnostic) {}
+	if !assert.NoError(t, GenerateJSONSchema(nil, inline, opts), fixture) {
+		return nil
+	}
+
+	metaSchema, err := spec.JSONSchemaDraft04()
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	result := make(map[string]map[string]interface{})
+	for _, f := range fs.Files() {
+		if !assert.True(t, strings.HasPrefix(f.Path, "api/jsonschema/"), f.Path) {
+			continue
+		}
+		var doc map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(f.Content, &doc), f.Path) {
+			assert.NoError(t, validate.AgainstSchema(metaSchema, doc, strfmt.Default), f.Path)
+			assert.Equal(t, jsonSchemaDraft04URI, doc["$schema"], f.Path)
+			result[strings.TrimPrefix(f.Path, "api/jsonschema/")] = doc
+		}
+	}
+	return result
+}
+
+// schemaKeywords collects the keywords used by a schema and its sub schemas, property names are skipped
+func schemaKeywords(schema map[string]interface{}, keywords map[string]bool) {
+	for k, v := range schema {
+		keywords[k] = true
+		switch k {
+		case "properties", "patternProperties", "definitions":
+			if props, ok := v.(map[string]interface{}); ok {
+				for _, prop := range props {
+					if sub, ok := prop.(map[string]interface{}); ok {
+						schemaKeywords(sub, keywords)
+					}
+				}
+			}
+		case "items", "additionalItems", "additionalProperties", "not", "allOf", "anyOf", "oneOf":
+			switch sub := v.(type) {
+			case map[string]interface{}:
+				schemaKeywords(sub, keywords)
+			case []interface{}:
+				for _, s := range sub {
+					if m, ok := s.(map[string]interface{}); ok {
+						schemaKeywords(m, keywords)
+					}
+				}
+			}
+		}
+	}
+}
+
+// schemaRefs collects the values of the references in a schema
+func schemaRefs(node interface{}, refs map[string]bool) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, v := range n {
+			if s, ok := v.(string); ok && k == "$ref" {
+				refs[s] = true
+			}
+			schemaRefs(v, refs)
+		}
+	case []interface{}:
+		for _, v := range n {
+			schemaRefs(v, refs)
+		}
+	}
+}
+
+func TestJSONSchemaFiles(t *testing.T) {
+	for _, fixture := range jsonSchemaFixtures {
+		docs := renderJSONSchemas(t, fixture, false)
+		assert.NotEmpty(t, docs, fixture)
+		checkJSONSchemaFiles(t, docs)
+	}
+}
+
+func checkJSONSchemaFiles(t *testing.T, docs map[string]map[string]interface{}) {
+	for name, doc := range docs {
+		keywords := make(map[string]bool)
+		schemaKeywords(doc, keywords)
+		for _, k := range swaggerSchemaKeywords {
+			assert.False(t, keywords[k], "%s uses %s", name, k)
+		}
+		for k := range keywords {
+			assert.False(t, strings.HasPrefix(k, "x-"), "%s uses %s", name, k)
+		}
+		assert.Nil(t, doc["definitions"], name)
+
+		// the references point to the files of the other definitions
+		refs := make(map[string]bool)
+		schemaRefs(doc, refs)
+		for ref := range refs {
+			_, ok := docs[ref]
+			assert.True(t, ok, "%s refers to %s", name, ref)
+		}
+	}
+}
+
+func TestJSONSchemaInline(t *testing.T) {
+	for _, fixture := range jsonSchemaFixtures {
+		docs := renderJSONSchemas(t, fixture, true)
+		assert.NotEmpty(t, docs, fixture)
+		checkJSONSchemaInline(t, docs)
+	}
+}
+
+func checkJSONSchemaInline(t *testing.T, docs map[string]map[string]interface{}) {
+	for name, doc := range docs {
+		definitions, _ := doc["definitions"].(map[string]interface{})
+		refs := make(map[string]bool)
+		schemaRefs(doc, refs)
+		for ref := range refs {
+			if ref == "#" {
+				continue
+			}
+			if assert.True(t, strings.HasPrefix(ref, "#/definitions/"), "%s refers to %s", name, ref) {
+				_, ok := definitions[strings.TrimPrefix(ref, "#/definitions/")]
+				assert.True(t, ok, "%s refers to %s", name, ref)
+			}
+		}
+	}
+}
+
+func TestJSONSchemaValidatesValues(t *testing.T) {
+	docs := renderJSONSchemas(t, "../fixtures/codegen/jsonschema.yml", true)
+	dog, ok := docs["dog.json"]
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, []interface{}{"name", "petType"}, dog["definitions"].(map[string]interface{})["pet"].(map[string]interface{})["required"])
+
+	bb, _ := json.Marshal(dog)
+	var schema spec.Schema
+	if !assert.NoError(t, json.Unmarshal(bb, &schema)) {
+		return
+	}
+	// the validator needs the references resolved, they are resolved in the document itself
+	if !assert.NoError(t, spec.ExpandSchema(&schema, dog, nil)) {
+		return
+	}
+	valid := map[string]interface{}{"name": "rex", "petType": "dog", "packSize": float64(3)}
+	assert.NoError(t, validate.AgainstSchema(&schema, valid, strfmt.Default))
+	for _, invalid := range []map[string]interface{}{
+		{"petType": "dog"},
+		{"name": "rex", "petType": "dog", "packSize": float64(-1)},
+		{"name": "rex", "petType": "dog", "owner": map[string]interface{}{"name": float64(1)}},
+	} {
+		assert.Error(t, validate.AgainstSchema(&schema, invalid, strfmt.Default), "value: %v", invalid)
+	}
+}
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -31,17 +31,32 @@ func init() {
 // GenerateModel generates a model file for a schema defintion
 func GenerateModel(modelNames []string, includeModel, includeValidator bool, opts GenOpts) error {
 	// Load the spec
-	specPath, specDoc, err := loadSpec(opts.Spec)
+	if err := opts.ModelHelpers.validate(); err != nil {
+		return err
+	}
+	specPath, specDoc, err := loadSpec(opts)
+	if err != nil {
+		return err
+	}
+	types, err := newTypeResolver(opts.FormatMappings)
 	if err != nil {
 		return err
 	}
+	definitions := definitionNames(specDoc)
 
 	if len(modelNames) == 0 {
 		for k := range specDoc.Spec().Definitions {
 			modelNames = append(modelNames, k)
 		}
+		sort.Strings(modelNames)
 	}
 
+	concurrency := opts.Concurrency
+	if opts.DumpData {
+		concurrency = 1
+	}
+
+	var jobs []generationJob
 	for _, modelName := range modelNames {
 		// lookup schema
 		model, ok := specDoc.Spec().Definitions[modelName]
@@ -50,37 +65,97 @@ func GenerateModel(modelNames []string, includeModel, includeValidator bool, opt
 		}
 
 		// generate files
-		generator := modelGenerator{
+		generator := &modelGenerator{
 			Name:             modelName,
 			Model:            model,
 			SpecDoc:          specDoc,
+			Types:            types,
+			Definitions:      definitions,
 			Target:           filepath.Join(opts.Target, opts.ModelPackage),
 			IncludeModel:     includeModel,
 			IncludeValidator: includeValidator,
+			Helpers:          opts.ModelHelpers,
 			DumpData:         opts.DumpData,
 		}
+		jobs = append(jobs, generationJob{
+			Name: "model " + modelName,
+			Run: func(out *output) error {
+				generator.out = out
+				return generator.Generate()
+			},
+		})
+	}
+
+	return runJobs(newOutput(opts), concurrency, jobs)
+}
+
+// RenderModel renders the model files for the schema definitions in memory.
+// The file system and diagnostics handler in the options are replaced to collect the results.
+func RenderModel(modelNames []string, includeModel, includeValidator bool, opts GenOpts) ([]GeneratedFile, []Diagnostic, error) {
+	fs := NewMemFileSystem()
+	var diags []Diagnostic
+	opts.FileSystem = fs
+	opts.Diagnostics = collectDiagnostics(&diags)
+	opts.DumpData = false
+
+	err := GenerateModel(modelNames, includeModel, includeValidator, opts)
+	return fs.Files(), diags, err
+}
 
-		if err := generator.Generate(); err != nil {
-			return err
+// ModelData returns the data the model templates get rendered with, this is what --dump-data prints
+func ModelData(modelNames []string, opts GenOpts) ([]interface{}, error) {
+	specPath, specDoc, err := loadSpec(opts)
+	if err != nil {
+		return nil, err
+	}
+	types, err := newTypeResolver(opts.FormatMappings)
+	if err != nil {
+		return nil, err
+	}
+	definitions := definitionNames(specDoc)
+
+	if len(modelNames) == 0 {
+		for k := range specDoc.Spec().Definitions {
+			modelNames = append(modelNames, k)
 		}
+		sort.Strings(modelNames)
 	}
 
-	return nil
+	var result []interface{}
+	for _, modelName := range modelNames {
+		model, ok := specDoc.Spec().Definitions[modelName]
+		if !ok {
+			return nil, fmt.Errorf("model %q not found in definitions in %s", modelName, specPath)
+		}
+		mod := makeCodegenModel(types, modelName, filepath.Join(opts.Target, opts.ModelPackage), model, specDoc)
+		if err := types.addModelHelpers(mod, opts.ModelHelpers, definitions); err != nil {
+			return nil, err
+		}
+		result = append(result, swag.ToDynamicJSON(mod))
+	}
+	return result, nil
 }
 
 type modelGenerator struct {
 	Name             string
 	Model            spec.Schema
 	SpecDoc          *spec.Document
+	Types            *typeResolver
+	Definitions      map[string]struct{}
 	Target           string
 	IncludeModel     bool
 	IncludeValidator bool
+	Helpers          ModelHelpers
 	Data             interface{}
 	DumpData         bool
+	out              *output
 }
 
 func (m *modelGenerator) Generate() error {
-	mod := makeCodegenModel(m.Name, m.Target, m.Model, m.SpecDoc)
+	mod := makeCodegenModel(m.Types, m.Name, m.Target, m.Model, m.SpecDoc)
+	if err := m.Types.addModelHelpers(mod, m.Helpers, m.Definitions); err != nil {
+		return fmt.Errorf("helpers: %s", err)
+	}
 	if m.DumpData {
 		bb, _ := json.MarshalIndent(swag.ToDynamicJSON(mod), "", " ")
 		fmt.Fprintln(os.Stdout, string(bb))
@@ -94,14 +169,21 @@ func (m *modelGenerator) Generate() error {
 			return fmt.Errorf("model: %s", err)
 		}
 	}
-	log.Println("generated model", m.Name)
+	m.out.Info("generated model", m.Name)
 
 	if m.IncludeValidator {
 		if err := m.generateValidator(); err != nil {
 			return fmt.Errorf("validator: %s", err)
 		}
 	}
-	log.Println("generated validator", m.Name)
+	m.out.Info("generated validator", m.Name)
+
+	if m.IncludeModel && m.Helpers.Enabled() {
+		if err := m.generateHelpers(); err != nil {
+			return fmt.Errorf("helpers: %s", err)
+		}
+		m.out.Info("generated helpers", m.Name)
+	}
 	return nil
 }
 
@@ -110,8 +192,8 @@ func (m *modelGenerator) generateValidator() error {
 	if err := modelValidatorTemplate.Execute(buf, m.Data); err != nil {
 		return err
 	}
-	log.Println("rendered validator template:", m.Name)
-	return writeToFile(m.Target, m.Name+"Validator", buf.Bytes())
+	m.out.Info("rendered validator template", m.Name)
+	return m.out.writeToFile(m.Target, m.Name+"Validator", buf.Bytes())
 }
 
 func (m *modelGenerator) generateModel() error {
@@ -120,12 +202,12 @@ func (m *modelGenerator) generateModel() error {
 	if err := modelTemplate.Execute(buf, m.Data); err != nil {
 		return err
 	}
-	log.Println("rendered model template:", m.Name)
+	m.out.Info("rendered model template", m.Name)
 
-	return writeToFile(m.Target, m.Name, buf.Bytes())
+	return m.out.writeToFile(m.Target, m.Name, buf.Bytes())
 }
 
-func makeCodegenModel(name, pkg string, schema spec.Schema, specDoc *spec.Document) *genModel {
+func makeCodegenModel(types *typeResolver, name, pkg string, schema spec.Schema, specDoc *spec.Document) *genModel {
 	receiver := "m"
 	props := make(map[string]genModelProperty)
 	for pn, p := range schema.Properties {
@@ -137,6 +219,7 @@ func makeCodegenModel(name, pkg string, schema spec.Schema, specDoc *spec.Docume
 			}
 		}
 		props[swag.ToJSONName(pn)] = makeGenModelProperty(
+			types,
 			"\""+pn+"\"",
 			swag.ToJSONName(pn),
 			swag.ToGoName(pn),
@@ -151,7 +234,7 @@ func makeCodegenModel(name, pkg string, schema spec.Schema, specDoc *spec.Docume
 			tn := filepath.Base(p.Ref.GetURL().Fragment)
 			p = specDoc.Spec().Definitions[tn]
 		}
-		mod := makeCodegenModel(name, pkg, p, specDoc)
+		mod := makeCodegenModel(types, name, pkg, p, specDoc)
 		if mod != nil {
 			for _, prop := range mod.Properties {
 				props[prop.ParamName] = prop
@@ -167,6 +250,12 @@ func makeCodegenModel(name, pkg string, schema spec.Schema, specDoc *spec.Docume
 		}
 		properties = append(properties, v)
 	}
+	sort.Sort(genModelPropertySlice(properties))
+
+	var propertyTypes []string
+	for _, p := range properties {
+		propertyTypes = append(propertyTypes, p.DataType)
+	}
 
 	return &genModel{
 		Package:        filepath.Base(pkg),
@@ -178,6 +267,7 @@ func makeCodegenModel(name, pkg string, schema spec.Schema, specDoc *spec.Docume
 		DocString:      modelDocString(swag.ToGoName(name), schema.Description),
 		HumanClassName: swag.ToHumanNameLower(swag.ToGoName(name)),
 		DefaultImports: []string{"github.com/go-swagger/go-swagger/strfmt"},
+		Imports:        types.typeImports(propertyTypes...),
 		HasValidations: hasValidations,
 	}
 }
@@ -194,31 +284,34 @@ type genModel struct {
 	Imports        map[string]string  //`json:"imports,omitempty"`
 	DefaultImports []string           //`json:"defaultImports,omitempty"`
 	HasValidations bool               //`json:"hasValidatins,omitempty"`
+	Helpers        ModelHelpers       //`json:"helpers,omitempty"`
+	Constructor    string             //`json:"constructor,omitempty"`
+	OptionType     string             //`json:"optionType,omitempty"`
 }
 
 func modelDocString(className, desc string) string {
 	return commentedLines(fmt.Sprintf("%s %s", className, desc))
 }
 
-func makeGenModelProperty(path, paramName, accessor, receiver, indexVar, valueExpression string, schema spec.Schema, required bool) genModelProperty {
+func makeGenModelProperty(types *typeResolver, path, paramName, accessor, receiver, indexVar, valueExpression string, schema spec.Schema, required bool) genModelProperty {
 	// log.Printf("property: (path %s) (param %s) (accessor %s) (receiver %s) (indexVar %s) (expr %s) required %t", path, paramName, accessor, receiver, indexVar, valueExpression, required)
 	ex := ""
 	if schema.Example != nil {
 		ex = fmt.Sprintf("%#v", schema.Example)
 	}
 
-	ctx := makeGenValidations(modelValidations(path, paramName, accessor, indexVar, valueExpression, "", required, schema))
+	ctx := makeGenValidations(modelValidations(types, path, paramName, accessor, indexVar, valueExpression, "", required, schema))
 
 	singleSchemaSlice := schema.Items != nil && schema.Items.Schema != nil
 	var items []genModelProperty
 	if singleSchemaSlice {
 		ctx.HasSliceValidations = true
 		items = []genModelProperty{
-			makeGenModelProperty("fmt.Sprintf(\"%s.%v\", "+path+", "+indexVar+")", paramName, accessor, receiver, indexVar+"i", valueExpression+"["+indexVar+"]", *schema.Items.Schema, false),
+			makeGenModelProperty(types, "fmt.Sprintf(\"%s.%v\", "+path+", "+indexVar+")", paramName, accessor, receiver, indexVar+"i", valueExpression+"["+indexVar+"]", *schema.Items.Schema, false),
 		}
 	} else if schema.Items != nil {
 		for _, s := range schema.Items.Schemas {
-			items = append(items, makeGenModelProperty("fmt.Sprintf(\"%s.%v\", "+path+", "+indexVar+")", paramName, accessor, receiver, indexVar+"i", valueExpression+"["+indexVar+"]", s, false))
+			items = append(items, makeGenModelProperty(types, "fmt.Sprintf(\"%s.%v\", "+path+", "+indexVar+")", paramName, accessor, receiver, indexVar+"i", valueExpression+"["+indexVar+"]", s, false))
 		}
 	}
 
@@ -228,12 +321,12 @@ func makeGenModelProperty(path, paramName, accessor, receiver, indexVar, valueEx
 	hasAdditionalItems := allowsAdditionalItems && !singleSchemaSlice
 	var additionalItems *genModelProperty
 	if schema.AdditionalItems != nil && schema.AdditionalItems.Schema != nil {
-		it := makeGenModelProperty("fmt.Sprintf(\"%s.%v\", "+path+", "+indexVar+")", paramName, accessor, receiver, indexVar+"i", valueExpression+"["+indexVar+"]", *schema.AdditionalItems.Schema, false)
+		it := makeGenModelProperty(types, "fmt.Sprintf(\"%s.%v\", "+path+", "+indexVar+")", paramName, accessor, receiver, indexVar+"i", valueExpression+"["+indexVar+"]", *schema.AdditionalItems.Schema, false)
 		additionalItems = &it
 	}
 
 	ctx.HasSliceValidations = len(items) > 0 || hasAdditionalItems
-	ctx.HasValidations = ctx.HasValidations || ctx.HasSliceValidations
+	ctx.HasValidations = ctx.HasValidations || ctx.HasSliceValidations || schema.ReadOnly
 
 	xmlName := paramName
 	if schema.XML != nil {
@@ -253,6 +346,8 @@ func makeGenModelProperty(path, paramName, accessor, receiver, indexVar, valueEx
 		Description:     schema.Description,
 		ReceiverName:    receiver,
 		IsComplexObject: !ctx.IsPrimitive && !ctx.IsCustomFormatter && !ctx.IsContainer,
+		IsModel:         types.isModelType(ctx.Type),
+		ReadOnly:        schema.ReadOnly,
 
 		HasAdditionalItems:    hasAdditionalItems,
 		AllowsAdditionalItems: allowsAdditionalItems,
@@ -282,6 +377,7 @@ type genModelProperty struct {
 	Location              string             //`json:"location,omitempty"`
 	ReceiverName          string             //`json:"receiverName,omitempty"`
 	IsComplexObject       bool               //`json:"isComplex,omitempty"` // not slice, custom formatter or primitive
+	IsModel               bool               //`json:"isModel,omitempty"` // a generated model, these have a ValidateFor method
 	SingleSchemaSlice     bool               //`json:"singleSchemaSlice,omitempty"`
 	Items                 []genModelProperty //`json:"items,omitempty"`
 	ItemsLen              int                //`json:"itemsLength,omitempty"`
@@ -290,13 +386,22 @@ type genModelProperty struct {
 	AdditionalItems       *genModelProperty  //`json:"additionalItems,omitempty"`
 	Object                *genModelProperty  //`json:"object,omitempty"`
 	XMLName               string             //`json:"xmlName,omitempty"`
+	ReadOnly              bool               //`json:"readOnly,omitempty"`
+	EqualCode             string             //`json:"equalCode,omitempty"`
+	DeepCopyCode          string             //`json:"deepCopyCode,omitempty"`
 }
 
-func modelValidations(path, paramName, accessor, indexVar, valueExpression, pkg string, required bool, model spec.Schema) commonValidations {
-	tpe := typeForSchema(&model, pkg)
+type genModelPropertySlice []genModelProperty
+
+func (g genModelPropertySlice) Len() int           { return len(g) }
+func (g genModelPropertySlice) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g genModelPropertySlice) Less(i, j int) bool { return g[i].ParamName < g[j].ParamName }
+
+func modelValidations(types *typeResolver, path, paramName, accessor, indexVar, valueExpression, pkg string, required bool, model spec.Schema) commonValidations {
+	tpe := types.typeForSchema(&model, pkg)
 
 	_, isPrimitive := primitives[tpe]
-	_, isCustomFormatter := customFormatters[tpe]
+	isCustomFormatter := types.isCustomFormatter(tpe)
 
 	return commonValidations{
 		propertyDescriptor: propertyDescriptor{
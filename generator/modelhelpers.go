@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/go-swagger/go-swagger/swag"
+)
+
+var modelHelpersTemplate *template.Template
+
+func init() {
+	bh, _ := Asset("templates/modelhelpers.gotmpl")
+	modelHelpersTemplate = template.Must(template.New("modelhelpers").Parse(string(bh)))
+}
+
+// the styles of constructors that can be generated for a model
+const (
+	// BuilderFluent generates a New function and a With method for every property that returns the model
+	BuilderFluent = "fluent"
+	// BuilderOptions generates a New function that takes functional options, with an option for every property
+	BuilderOptions = "options"
+)
+
+// ModelHelpers configures the optional helpers that are generated for every model
+type ModelHelpers struct {
+	// Equal generates an Equal method that compares models property by property
+	Equal bool
+	// DeepCopy generates a DeepCopy method that doesn't share slices, maps or pointers with the original
+	DeepCopy bool
+	// Builder the style of the constructors, BuilderFluent or BuilderOptions, none are generated when empty
+	Builder string
+}
+
+// Enabled returns true when at least one helper needs to be generated
+func (h ModelHelpers) Enabled() bool {
+	return h.Equal || h.DeepCopy || h.Builder != ""
+}
+
+func (h ModelHelpers) validate() error {
+	switch h.Builder {
+	case "", BuilderFluent, BuilderOptions:
+		return nil
+	}
+	return fmt.Errorf("unknown builder style %q, expected %s or %s", h.Builder, BuilderFluent, BuilderOptions)
+}
+
+func (m *modelGenerator) generateHelpers() error {
+	buf := bytes.NewBuffer(nil)
+	if err := modelHelpersTemplate.Execute(buf, m.Data); err != nil {
+		return err
+	}
+	m.out.Info("rendered helpers template", m.Name)
+	return m.out.writeToFile(m.Target, m.Name+"Helpers", buf.Bytes())
+}
+
+// addModelHelpers prepares the code for the equality and deep copy of every property of the model,
+// the definitions are the names of the models in the package, the constructors can't use those
+func (t *typeResolver) addModelHelpers(mod *genModel, helpers ModelHelpers, definitions map[string]struct{}) error {
+	mod.Helpers = helpers
+	for i := range mod.Properties {
+		prop := &mod.Properties[i]
+		lhs := mod.ReceiverName + "." + prop.PropertyName
+		if helpers.Equal {
+			prop.EqualCode = t.equalCode(prop.DataType, lhs, "other."+prop.PropertyName, 0)
+		}
+		if helpers.DeepCopy {
+			prop.DeepCopyCode = t.deepCopyCode(prop.DataType, "res."+prop.PropertyName, lhs, 0)
+		}
+	}
+	if helpers.Builder != "" {
+		return builderNames(mod, helpers.Builder, definitions)
+	}
+	return nil
+}
+
+// definitionNames returns the go names of the definitions in the spec
+func definitionNames(specDoc *spec.Document) map[string]struct{} {
+	result := make(map[string]struct{}, len(specDoc.Spec().Definitions))
+	for k := range specDoc.Spec().Definitions {
+		result[swag.ToGoName(k)] = struct{}{}
+	}
+	return result
+}
+
+// builderNames picks the names of the constructor and the option type of a model.
+// These live next to the models, so a Pet gets a NewPetModel constructor when there is a NewPet definition.
+func builderNames(mod *genModel, builder string, definitions map[string]struct{}) error {
+	pick := func(candidates ...string) (string, error) {
+		for _, name := range candidates {
+			if _, taken := definitions[name]; !taken {
+				return name, nil
+			}
+		}
+		return "", fmt.Errorf("the builder for %s needs one of %s, but there are definitions with these names", mod.ClassName, strings.Join(candidates, ", "))
+	}
+
+	var err error
+	if mod.Constructor, err = pick("New"+mod.ClassName, "New"+mod.ClassName+"Model"); err != nil {
+		return err
+	}
+	if builder != BuilderOptions {
+		return nil
+	}
+	if mod.OptionType, err = pick(mod.ClassName+"Option", mod.ClassName+"ModelOption"); err != nil {
+		return err
+	}
+	for _, prop := range mod.Properties {
+		if _, taken := definitions[mod.ClassName+"With"+prop.PropertyName]; taken {
+			return fmt.Errorf("the builder for %s needs the option %sWith%s, but there is a definition with this name", mod.ClassName, mod.ClassName, prop.PropertyName)
+		}
+	}
+	return nil
+}
+
+// isComparableType returns true for the types that can be compared with ==
+func (t *typeResolver) isComparableType(tpe string) bool {
+	if _, ok := primitives[tpe]; ok {
+		return true
+	}
+	if tpe == "strfmt.Base64" {
+		return false
+	}
+	if t.isCustomFormatter(tpe) {
+		return true
+	}
+	return tpe == "strfmt.Duration"
+}
+
+func isTimeType(tpe string) bool {
+	return tpe == "strfmt.Date" || tpe == "strfmt.DateTime"
+}
+
+// isModelType returns true for the types that are generated models, these get their own Equal and DeepCopy methods
+func (t *typeResolver) isModelType(tpe string) bool {
+	if t.isComparableType(tpe) || isTimeType(tpe) || tpe == "strfmt.Base64" || tpe == "interface{}" {
+		return false
+	}
+	return !strings.HasPrefix(tpe, "[]") && !strings.HasPrefix(tpe, "map[") && !strings.HasPrefix(tpe, "*") && !strings.Contains(tpe, ".")
+}
+
+// equalCode renders the statements that return false when the values of lhs and rhs differ.
+// Both expressions need to be addressable, so models can be compared with their pointer receiver.
+func (t *typeResolver) equalCode(tpe, lhs, rhs string, depth int) string {
+	switch {
+	case strings.HasPrefix(tpe, "[]"):
+		idx := fmt.Sprintf("i%d", depth)
+		return fmt.Sprintf("if len(%s) != len(%s) {\nreturn false\n}\nfor %s := range %s {\n%s}\n",
+			lhs, rhs, idx, lhs, t.equalCode(tpe[2:], lhs+"["+idx+"]", rhs+"["+idx+"]", depth+1))
+	case strings.HasPrefix(tpe, "map[string]"):
+		k, lv, rv := fmt.Sprintf("k%d", depth), fmt.Sprintf("lv%d", depth), fmt.Sprintf("rv%d", depth)
+		return fmt.Sprintf("if len(%s) != len(%s) {\nreturn false\n}\nfor %s, %s := range %s {\n%s, ok := %s[%s]\nif !ok {\nreturn false\n}\n%s}\n",
+			lhs, rhs, k, lv, lhs, rv, rhs, k, t.equalCode(tpe[len("map[string]"):], lv, rv, depth+1))
+	case strings.HasPrefix(tpe, "*"):
+		return fmt.Sprintf("if (%s == nil) != (%s == nil) {\nreturn false\n}\nif %s != nil {\n%s}\n",
+			lhs, rhs, lhs, t.equalCode(tpe[1:], "(*"+lhs+")", "(*"+rhs+")", depth))
+	case t.isComparableType(tpe):
+		return fmt.Sprintf("if %s != %s {\nreturn false\n}\n", lhs, rhs)
+	case isTimeType(tpe):
+		return fmt.Sprintf("if !%s.Equal(%s.Time) {\nreturn false\n}\n", lhs, rhs)
+	case tpe == "strfmt.Base64":
+		return fmt.Sprintf("if !bytes.Equal(%s, %s) {\nreturn false\n}\n", lhs, rhs)
+	case t.isModelType(tpe):
+		return fmt.Sprintf("if !%s.Equal(&%s) {\nreturn false\n}\n", lhs, rhs)
+	}
+	return fmt.Sprintf("if !reflect.DeepEqual(%s, %s) {\nreturn false\n}\n", lhs, rhs)
+}
+
+// needsDeepCopy returns true when a plain assignment would share memory with the original value
+func (t *typeResolver) needsDeepCopy(tpe string) bool {
+	switch {
+	case strings.HasPrefix(tpe, "[]"), strings.HasPrefix(tpe, "map[string]"), strings.HasPrefix(tpe, "*"):
+		return true
+	case tpe == "strfmt.Base64":
+		return true
+	}
+	return t.isModelType(tpe)
+}
+
+// deepCopyCode renders the statements that replace the shallow copy in dst with a deep copy of src.
+// The values the properties refer to with an interface{} are shared, there is no way to copy those safely.
+func (t *typeResolver) deepCopyCode(tpe, dst, src string, depth int) string {
+	if !t.needsDeepCopy(tpe) {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(tpe, "[]"):
+		elem := tpe[2:]
+		idx := fmt.Sprintf("i%d", depth)
+		code := fmt.Sprintf("if %s != nil {\n%s = make(%s, len(%s))\ncopy(%s, %s)\n", src, dst, tpe, src, dst, src)
+		if t.needsDeepCopy(elem) {
+			code += fmt.Sprintf("for %s := range %s {\n%s}\n", idx, src, t.deepCopyCode(elem, dst+"["+idx+"]", src+"["+idx+"]", depth+1))
+		}
+		return code + "}\n"
+	case strings.HasPrefix(tpe, "map[string]"):
+		elem := tpe[len("map[string]"):]
+		k, v, c := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth), fmt.Sprintf("c%d", depth)
+		code := fmt.Sprintf("if %s != nil {\n%s = make(%s, len(%s))\nfor %s, %s := range %s {\n", src, dst, tpe, src, k, v, src)
+		if t.isModelType(elem) {
+			code += fmt.Sprintf("%s[%s] = *%s.DeepCopy()\n", dst, k, v)
+		} else if t.needsDeepCopy(elem) {
+			code += fmt.Sprintf("%s := %s\n%s%s[%s] = %s\n", c, v, t.deepCopyCode(elem, c, v, depth+1), dst, k, c)
+		} else {
+			code += fmt.Sprintf("%s[%s] = %s\n", dst, k, v)
+		}
+		return code + "}\n}\n"
+	case strings.HasPrefix(tpe, "*"):
+		c := fmt.Sprintf("c%d", depth)
+		return fmt.Sprintf("if %s != nil {\n%s := *%s\n%s%s = &%s\n}\n", src, c, src, t.deepCopyCode(tpe[1:], c, "(*"+src+")", depth+1), dst, c)
+	case tpe == "strfmt.Base64":
+		return fmt.Sprintf("if %s != nil {\n%s = append(strfmt.Base64(nil), %s...)\n}\n", src, dst, src)
+	}
+	return fmt.Sprintf("%s = *%s.DeepCopy()\n", dst, src)
+}
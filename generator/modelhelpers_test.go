@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderNames(t *testing.T) {
+	pet := func() *genModel {
+		return &genModel{ClassName: "Pet", Properties: []genModelProperty{{sharedParam: sharedParam{propertyDescriptor: propertyDescriptor{PropertyName: "Name"}}}}}
+	}
+	definitions := func(names ...string) map[string]struct{} {
+		result := make(map[string]struct{})
+		for _, n := range names {
+			result[n] = struct{}{}
+		}
+		return result
+	}
+
+	mod := pet()
+	if assert.NoError(t, builderNames(mod, BuilderOptions, definitions("Pet"))) {
+		assert.Equal(t, "NewPet", mod.Constructor)
+		assert.Equal(t, "PetOption", mod.OptionType)
+	}
+
+	mod = pet()
+	if assert.NoError(t, builderNames(mod, BuilderOptions, definitions("Pet", "NewPet", "PetOption"))) {
+		assert.Equal(t, "NewPetModel", mod.Constructor)
+		assert.Equal(t, "PetModelOption", mod.OptionType)
+	}
+
+	mod = pet()
+	if assert.NoError(t, builderNames(mod, BuilderFluent, definitions("Pet", "PetWithName"))) {
+		assert.Equal(t, "NewPet", mod.Constructor)
+		assert.Empty(t, mod.OptionType)
+	}
+
+	assert.Error(t, builderNames(pet(), BuilderFluent, definitions("Pet", "NewPet", "NewPetModel")))
+	assert.Error(t, builderNames(pet(), BuilderOptions, definitions("Pet", "PetOption", "PetModelOption")))
+	assert.Error(t, builderNames(pet(), BuilderOptions, definitions("Pet", "PetWithName")))
+}
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -31,23 +31,38 @@ func init() {
 // It also generates an operation handler interface that uses the parameter model for handling a valid request.
 // Allows for specifying a list of tags to include only certain tags for the generation
 func GenerateServerOperation(operationNames, tags []string, includeHandler, includeParameters bool, opts GenOpts) error {
+	return generateOperations(operationNames, tags, includeHandler, includeParameters, opts)
+}
+
+func generateOperations(operationNames, tags []string, includeHandler, includeParameters bool, opts GenOpts) error {
 	// Load the spec
-	specPath, specDoc, err := loadSpec(opts.Spec)
+	specPath, specDoc, err := loadSpec(opts)
+	if err != nil {
+		return err
+	}
+	types, err := newTypeResolver(opts.FormatMappings)
 	if err != nil {
 		return err
 	}
 
 	if len(operationNames) == 0 {
 		operationNames = specDoc.OperationIDs()
+		sort.Strings(operationNames)
+	}
+
+	concurrency := opts.Concurrency
+	if opts.DumpData {
+		concurrency = 1
 	}
 
+	var jobs []generationJob
 	for _, operationName := range operationNames {
 		operation, ok := specDoc.OperationForName(operationName)
 		if !ok {
 			return fmt.Errorf("operation %q not found in %s", operationName, specPath)
 		}
 
-		generator := operationGenerator{
+		generator := &operationGenerator{
 			Name:                 operationName,
 			APIPackage:           opts.APIPackage,
 			ModelsPackage:        opts.ModelPackage,
@@ -57,58 +72,85 @@ func GenerateServerOperation(operationNames, tags []string, includeHandler, incl
 			Operation:            *operation,
 			SecurityRequirements: specDoc.SecurityRequirementsFor(operation),
 			Principal:            opts.Principal,
+			Types:                types,
 			Target:               filepath.Join(opts.Target, opts.APIPackage),
 			Tags:                 tags,
 			IncludeHandler:       includeHandler,
 			IncludeParameters:    includeParameters,
 			DumpData:             opts.DumpData,
 		}
-		if err := generator.Generate(); err != nil {
-			return err
-		}
-	}
-	return nil
+		jobs = append(jobs, generationJob{
+			Name: "operation " + operationName,
+			Run: func(out *output) error {
+				generator.out = out
+				return generator.Generate()
+			},
+		})
+	}
+	return runJobs(newOutput(opts), concurrency, jobs)
 }
 
-// GenerateTestOperation generates test suits for operations
-func GenerateTestOperation(operationNames, tags []string, includeHandler, includeParameters bool, opts GenOpts) error {
-	// Load the spec
-	specPath, specDoc, err := loadSpec(opts.Spec)
+// RenderServerOperation renders the files for the operations in memory.
+// The file system and diagnostics handler in the options are replaced to collect the results.
+func RenderServerOperation(operationNames, tags []string, includeHandler, includeParameters bool, opts GenOpts) ([]GeneratedFile, []Diagnostic, error) {
+	fs := NewMemFileSystem()
+	var diags []Diagnostic
+	opts.FileSystem = fs
+	opts.Diagnostics = collectDiagnostics(&diags)
+	opts.DumpData = false
+
+	err := GenerateServerOperation(operationNames, tags, includeHandler, includeParameters, opts)
+	return fs.Files(), diags, err
+}
+
+// OperationData returns the data the operation templates get rendered with, this is what --dump-data prints
+func OperationData(operationNames, tags []string, opts GenOpts) ([]interface{}, error) {
+	specPath, specDoc, err := loadSpec(opts)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	types, err := newTypeResolver(opts.FormatMappings)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(operationNames) == 0 {
 		operationNames = specDoc.OperationIDs()
+		sort.Strings(operationNames)
 	}
 
+	var result []interface{}
 	for _, operationName := range operationNames {
 		operation, ok := specDoc.OperationForName(operationName)
 		if !ok {
-			return fmt.Errorf("operation %q not found in %s", operationName, specPath)
+			return nil, fmt.Errorf("operation %q not found in %s", operationName, specPath)
 		}
 
 		generator := operationGenerator{
 			Name:                 operationName,
 			APIPackage:           opts.APIPackage,
 			ModelsPackage:        opts.ModelPackage,
-			ClientPackage:        opts.ClientPackage,
-			ServerPackage:        opts.ServerPackage,
-			TestPackage:          opts.TestPackage,
 			Operation:            *operation,
 			SecurityRequirements: specDoc.SecurityRequirementsFor(operation),
 			Principal:            opts.Principal,
+			Types:                types,
 			Target:               filepath.Join(opts.Target, opts.APIPackage),
 			Tags:                 tags,
-			IncludeHandler:       includeHandler,
-			IncludeParameters:    includeParameters,
-			DumpData:             opts.DumpData,
 		}
-		if err := generator.Generate(); err != nil {
-			return err
+		operations, err := generator.codegenOperations()
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range operations {
+			result = append(result, swag.ToDynamicJSON(op))
 		}
 	}
-	return nil
+	return result, nil
+}
+
+// GenerateTestOperation generates test suits for operations
+func GenerateTestOperation(operationNames, tags []string, includeHandler, includeParameters bool, opts GenOpts) error {
+	return generateOperations(operationNames, tags, includeHandler, includeParameters, opts)
 }
 
 type operationGenerator struct {
@@ -122,6 +164,7 @@ type operationGenerator struct {
 	Operation            spec.Operation
 	SecurityRequirements []spec.SecurityRequirement
 	Principal            string
+	Types                *typeResolver
 	Target               string
 	Tags                 []string
 	data                 interface{}
@@ -130,31 +173,53 @@ type operationGenerator struct {
 	IncludeHandler       bool
 	IncludeParameters    bool
 	DumpData             bool
+	out                  *output
 }
 
-func (o *operationGenerator) Generate() error {
-	// Build a list of codegen operations based on the tags,
-	// the tag decides the actual package for an operation
-	// the user specified package serves as root for generating the directory structure
+// codegenOperations builds a list of codegen operations based on the tags,
+// the tag decides the actual package for an operation
+// the user specified package serves as root for generating the directory structure
+func (o *operationGenerator) codegenOperations() ([]genOperation, error) {
 	var operations []genOperation
 	authed := len(o.SecurityRequirements) > 0
+	add := func(pkg string) error {
+		op, err := makeCodegenOperation(o.Types, o.Name, pkg, o.ModelsPackage, o.Principal, o.Target, o.Operation, authed)
+		if err != nil {
+			return err
+		}
+		operations = append(operations, op)
+		return nil
+	}
 	for _, tag := range o.Operation.Tags {
 		if len(o.Tags) == 0 {
-			operations = append(operations, makeCodegenOperation(o.Name, tag, o.ModelsPackage, o.Principal, o.Target, o.Operation, authed))
+			if err := add(tag); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		for _, ft := range o.Tags {
 			if ft == tag {
-				operations = append(operations, makeCodegenOperation(o.Name, tag, o.ModelsPackage, o.Principal, o.Target, o.Operation, authed))
+				if err := add(tag); err != nil {
+					return nil, err
+				}
 				break
 			}
 		}
 
 	}
 	if len(operations) == 0 {
-		operations = append(operations, makeCodegenOperation(o.Name, o.APIPackage, o.ModelsPackage, o.Principal, o.Target, o.Operation, authed))
+		if err := add(o.APIPackage); err != nil {
+			return nil, err
+		}
 	}
+	return operations, nil
+}
 
+func (o *operationGenerator) Generate() error {
+	operations, err := o.codegenOperations()
+	if err != nil {
+		return err
+	}
 	for _, op := range operations {
 		if o.DumpData {
 			bb, _ := json.MarshalIndent(swag.ToDynamicJSON(op), "", " ")
@@ -169,18 +234,18 @@ func (o *operationGenerator) Generate() error {
 			if err := o.generateHandler(); err != nil {
 				return fmt.Errorf("handler: %s", err)
 			}
-			log.Println("generated handler", op.Package+"."+op.ClassName)
+			o.out.Info("generated handler", op.Package+"."+op.ClassName)
 		}
 
 		if o.IncludeParameters && len(o.Operation.Parameters) > 0 {
 			if err := o.generateParameterModel(); err != nil {
 				return fmt.Errorf("parameters: %s", err)
 			}
-			log.Println("generated parameters", op.Package+"."+op.ClassName+"Parameters")
+			o.out.Info("generated parameters", op.Package+"."+op.ClassName+"Parameters")
 		}
 
 		if len(o.Operation.Parameters) == 0 {
-			log.Println("no parameters for operation", op.Package+"."+op.ClassName)
+			o.out.Info("no parameters for operation", op.Package+"."+op.ClassName)
 		}
 	}
 
@@ -193,13 +258,9 @@ func (o *operationGenerator) generateHandler() error {
 	if err := operationTemplate.Execute(buf, o.data); err != nil {
 		return err
 	}
-	log.Println("rendered handler template:", o.pkg+"."+o.cname)
+	o.out.Info("rendered handler template", o.pkg+"."+o.cname)
 
-	fp := filepath.Join(o.ServerPackage, o.Target)
-	if len(o.Operation.Tags) > 0 {
-		fp = filepath.Join(fp, o.pkg)
-	}
-	return writeToFile(fp, o.Name, buf.Bytes())
+	return o.out.writeToFile(o.packageDir(), o.Name, buf.Bytes())
 }
 
 func (o *operationGenerator) generateParameterModel() error {
@@ -208,22 +269,34 @@ func (o *operationGenerator) generateParameterModel() error {
 	if err := parameterTemplate.Execute(buf, o.data); err != nil {
 		return err
 	}
-	log.Println("rendered parameters template:", o.pkg+"."+o.cname+"Parameters")
+	o.out.Info("rendered parameters template", o.pkg+"."+o.cname+"Parameters")
+
+	return o.out.writeToFile(o.packageDir(), o.Name+"Parameters", buf.Bytes())
+}
 
-	fp := filepath.Join(o.ServerPackage, o.Target)
+// packageDir is the directory of the package the operation is generated in,
+// the api package of the server package in the target or the package of its tag inside that
+func (o *operationGenerator) packageDir() string {
+	fp := filepath.Join(filepath.Dir(o.Target), o.ServerPackage, filepath.Base(o.Target))
 	if len(o.Operation.Tags) > 0 {
 		fp = filepath.Join(fp, o.pkg)
 	}
-	return writeToFile(fp, o.Name+"Parameters", buf.Bytes())
+	return fp
 }
 
-func makeCodegenOperation(name, pkg, modelsPkg, principal, target string, operation spec.Operation, authorized bool) genOperation {
+func makeCodegenOperation(types *typeResolver, name, pkg, modelsPkg, principal, target string, operation spec.Operation, authorized bool) (genOperation, error) {
 	receiver := "o"
+	baseImp, err := baseImport(filepath.Join(target, ".."))
+	if err != nil {
+		return genOperation{}, err
+	}
 
 	var params, qp, pp, hp, fp []genParameter
 	var hasQueryParams bool
+	var usedTypes []string
 	for _, p := range operation.Parameters {
-		cp := makeCodegenParameter(receiver, modelsPkg, p)
+		cp := makeCodegenParameter(types, receiver, modelsPkg, p)
+		usedTypes = append(usedTypes, cp.Type)
 		if cp.IsQueryParam {
 			hasQueryParams = true
 			qp = append(qp, cp)
@@ -244,12 +317,13 @@ func makeCodegenOperation(name, pkg, modelsPkg, principal, target string, operat
 	var returnsPrimitive, returnsFormatted, returnsContainer, returnsMap bool
 	if operation.Responses != nil {
 		if r, ok := operation.Responses.StatusCodeResponses[200]; ok {
-			tn := typeForSchema(r.Schema, modelsPkg)
+			tn := types.typeForSchema(r.Schema, modelsPkg)
 			_, returnsPrimitive = primitives[tn]
-			_, returnsFormatted = customFormatters[tn]
+			returnsFormatted = types.isCustomFormatter(tn)
 			returnsContainer = r.Schema.Items != nil || r.Schema.Type.Contains("array")
 			returnsMap = strings.HasPrefix(tn, "map")
 			successModel = tn
+			usedTypes = append(usedTypes, tn)
 		}
 	}
 
@@ -258,7 +332,7 @@ func makeCodegenOperation(name, pkg, modelsPkg, principal, target string, operat
 		prin = "interface{}"
 	}
 
-	zero, ok := zeroes[successModel]
+	zero, ok := types.zeroes[successModel]
 	if !ok {
 		zero = "nil"
 	}
@@ -272,10 +346,11 @@ func makeCodegenOperation(name, pkg, modelsPkg, principal, target string, operat
 		ReceiverName:   receiver,
 		HumanClassName: swag.ToHumanNameLower(swag.ToGoName(name)),
 		DefaultImports: []string{
-			filepath.ToSlash(filepath.Join(baseImport(filepath.Join(target, "..")), modelsPkg)),
+			filepath.ToSlash(filepath.Join(baseImp, modelsPkg)),
 			"github.com/go-swagger/go-swagger/httpkit/middleware",
 			"github.com/go-swagger/go-swagger/strfmt",
 		},
+		Imports:              types.typeImports(usedTypes...),
 		Params:               params,
 		Summary:              operation.Summary,
 		QueryParams:          qp,
@@ -292,7 +367,7 @@ func makeCodegenOperation(name, pkg, modelsPkg, principal, target string, operat
 		ReturnsComplexObject: !returnsPrimitive && !returnsFormatted && !returnsContainer && !returnsMap,
 		Authorized:           authorized,
 		Principal:            prin,
-	}
+	}, nil
 }
 
 func operationDocString(name string, operation spec.Operation) string {
@@ -349,12 +424,13 @@ type genOperation struct {
 	HasFileParams  bool           //`json:"hasFileParams,omitempty"`  // -
 }
 
-func makeCodegenParameter(receiver, modelsPkg string, param spec.Parameter) genParameter {
+func makeCodegenParameter(types *typeResolver, receiver, modelsPkg string, param spec.Parameter) genParameter {
 	var ctx sharedParam
 	var child *genParameterItem
 
 	if param.In == "body" {
 		ctx = makeGenValidations(modelValidations(
+			types,
 			"\""+swag.ToJSONName(param.Name)+"\"",
 			swag.ToJSONName(param.Name),
 			swag.ToGoName(param.Name),
@@ -365,16 +441,17 @@ func makeCodegenParameter(receiver, modelsPkg string, param spec.Parameter) genP
 			*param.Schema))
 
 	} else {
-		ctx = makeGenValidations(paramValidations(receiver, param))
+		ctx = makeGenValidations(paramValidations(types, receiver, param))
 		thisItem := genParameterItem{}
 		thisItem.sharedParam = ctx
 		thisItem.ValueExpression = ctx.IndexVar + "c"
 		thisItem.CollectionFormat = param.CollectionFormat
-		thisItem.Converter = stringConverters[ctx.Type]
+		thisItem.Converter = types.stringConverters[ctx.Type]
 		thisItem.Location = param.In
 
 		if param.Items != nil {
 			it := makeCodegenParamItem(
+				types,
 				"fmt.Sprintf(\"%s.%v\", "+ctx.Path+", "+ctx.IndexVar+")",
 				ctx.ParamName,
 				ctx.PropertyName,
@@ -401,7 +478,7 @@ func makeCodegenParameter(receiver, modelsPkg string, param spec.Parameter) genP
 		CollectionFormat: param.CollectionFormat,
 		Child:            child,
 		Location:         param.In,
-		Converter:        stringConverters[ctx.Type],
+		Converter:        types.stringConverters[ctx.Type],
 	}
 }
 
@@ -423,20 +500,21 @@ type genParameter struct {
 	Location         string            //`json:"location,omitempty"`
 }
 
-func makeCodegenParamItem(path, paramName, accessor, indexVar, valueExpression string, parent genParameterItem, items spec.Items) genParameterItem {
-	ctx := makeGenValidations(paramItemValidations(path, paramName, accessor, indexVar, valueExpression, items))
+func makeCodegenParamItem(types *typeResolver, path, paramName, accessor, indexVar, valueExpression string, parent genParameterItem, items spec.Items) genParameterItem {
+	ctx := makeGenValidations(paramItemValidations(types, path, paramName, accessor, indexVar, valueExpression, items))
 
 	res := genParameterItem{}
 	res.sharedParam = ctx
 	res.CollectionFormat = items.CollectionFormat
 	res.Parent = &parent
-	res.Converter = stringConverters[ctx.Type]
+	res.Converter = types.stringConverters[ctx.Type]
 	res.Location = parent.Location
 	res.ValueExpression = "value"
 
 	var child *genParameterItem
 	if items.Items != nil {
 		it := makeCodegenParamItem(
+			types,
 			"fmt.Sprintf(\"%s.%v\", "+ctx.Path+", "+ctx.IndexVar+")",
 			ctx.ParamName,
 			ctx.PropertyName,
@@ -466,10 +544,10 @@ type sharedParam struct {
 	propertyDescriptor
 }
 
-func paramItemValidations(path, paramName, accessor, indexVar, valueExpression string, items spec.Items) commonValidations {
-	tpe := resolveSimpleType(items.Type, items.Format, items.Items)
+func paramItemValidations(types *typeResolver, path, paramName, accessor, indexVar, valueExpression string, items spec.Items) commonValidations {
+	tpe := types.resolveSimpleType(items.Type, items.Format, items.Items)
 	_, isPrimitive := primitives[tpe]
-	_, isCustomFormatter := customFormatters[tpe]
+	isCustomFormatter := types.isCustomFormatter(tpe)
 
 	return commonValidations{
 		propertyDescriptor: propertyDescriptor{
@@ -503,13 +581,13 @@ func paramItemValidations(path, paramName, accessor, indexVar, valueExpression s
 	}
 }
 
-func paramValidations(receiver string, param spec.Parameter) commonValidations {
+func paramValidations(types *typeResolver, receiver string, param spec.Parameter) commonValidations {
 	accessor := swag.ToGoName(param.Name)
 	paramName := swag.ToJSONName(param.Name)
 
-	tpe := typeForParameter(param)
+	tpe := types.typeForParameter(param)
 	_, isPrimitive := primitives[tpe]
-	_, isCustomFormatter := customFormatters[tpe]
+	isCustomFormatter := types.isCustomFormatter(tpe)
 
 	return commonValidations{
 		propertyDescriptor: propertyDescriptor{
@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FileSystem is the sink the generator writes the files it renders to.
+// The default implementation writes to disk, NewMemFileSystem creates one that keeps everything in memory.
+type FileSystem interface {
+	// Exists returns true when a file exists at the specified path
+	Exists(path string) bool
+	// WriteFile writes the content to the specified path, creating intermediate directories when required
+	WriteFile(path string, content []byte) error
+}
+
+type osFileSystem struct{}
+
+func (osFileSystem) Exists(path string) bool {
+	_, err := os.Stat(path)
+	return !os.IsNotExist(err)
+}
+
+func (osFileSystem) WriteFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// GeneratedFile represents a file rendered by the generator
+type GeneratedFile struct {
+	Path    string
+	Content []byte
+}
+
+// MemFileSystem is a file system that keeps the generated files in memory
+type MemFileSystem struct {
+	lock  sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemFileSystem creates a new in memory file system
+func NewMemFileSystem() *MemFileSystem {
+	return &MemFileSystem{files: make(map[string][]byte)}
+}
+
+// Exists returns true when a file has been written to the specified path
+func (m *MemFileSystem) Exists(path string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	_, ok := m.files[filepath.Clean(path)]
+	return ok
+}
+
+// WriteFile stores the content for the specified path
+func (m *MemFileSystem) WriteFile(path string, content []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.files[filepath.Clean(path)] = append([]byte(nil), content...)
+	return nil
+}
+
+// Files returns the files written to this file system, sorted by path
+func (m *MemFileSystem) Files() []GeneratedFile {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var result []GeneratedFile
+	for k, v := range m.files {
+		result = append(result, GeneratedFile{Path: k, Content: v})
+	}
+	sort.Sort(byPath(result))
+	return result
+}
+
+type byPath []GeneratedFile
+
+func (b byPath) Len() int           { return len(b) }
+func (b byPath) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byPath) Less(i, j int) bool { return b[i].Path < b[j].Path }
+
+// DiagnosticLevel the severity of a diagnostic
+type DiagnosticLevel int
+
+const (
+	// DiagnosticInfo informs about progress, like a file that has been rendered
+	DiagnosticInfo DiagnosticLevel = iota
+	// DiagnosticWarning something went wrong but the generator could continue, like a file that couldn't be formatted
+	DiagnosticWarning
+	// DiagnosticError something went wrong and the generator couldn't produce the file
+	DiagnosticError
+)
+
+func (d DiagnosticLevel) String() string {
+	switch d {
+	case DiagnosticWarning:
+		return "warning"
+	case DiagnosticError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// Diagnostic is a message the generator reports while it renders files
+type Diagnostic struct {
+	Level   DiagnosticLevel
+	Target  string
+	Message string
+	Err     error
+}
+
+func (d Diagnostic) String() string {
+	msg := d.Message
+	if d.Target != "" {
+		msg = fmt.Sprintf("%s: %s", msg, d.Target)
+	}
+	if d.Err != nil {
+		msg = fmt.Sprintf("%s (%v)", msg, d.Err)
+	}
+	return msg
+}
+
+// output writes the generated files to the configured file system and reports diagnostics
+type output struct {
+	fs     FileSystem
+	report func(Diagnostic)
+}
+
+func newOutput(opts GenOpts) *output {
+	out := &output{fs: opts.FileSystem, report: opts.Diagnostics}
+	if out.fs == nil {
+		out.fs = osFileSystem{}
+	}
+	if out.report == nil {
+		out.report = func(d Diagnostic) { log.Println(d.String()) }
+	}
+	return out
+}
+
+func (o *output) Info(message, target string) {
+	o.report(Diagnostic{Level: DiagnosticInfo, Message: message, Target: target})
+}
+
+func (o *output) Warn(message, target string, err error) {
+	o.report(Diagnostic{Level: DiagnosticWarning, Message: message, Target: target, Err: err})
+}
+
+func (o *output) fileExists(target, name string) bool {
+	return o.fs.Exists(filepath.Join(target, goFileName(name)))
+}
+
+func (o *output) writeToFileIfNotExist(target, name string, content []byte) error {
+	if o.fileExists(target, name) {
+		return nil
+	}
+	return o.writeToFile(target, name, content)
+}
+
+func (o *output) writeToFile(target, name string, content []byte) error {
+	ffn := goFileName(name)
+	res, err := formatGoFile(ffn, content)
+	if err != nil {
+		o.Warn("failed to format", filepath.Join(target, ffn), err)
+		return o.fs.WriteFile(filepath.Join(target, ffn), content)
+	}
+
+	return o.fs.WriteFile(filepath.Join(target, ffn), res)
+}
+
+// writeFile writes content that isn't go code as is, the name is used as the file name
+func (o *output) writeFile(target, name string, content []byte) error {
+	return o.fs.WriteFile(filepath.Join(target, name), content)
+}
+
+// collectDiagnostics returns a diagnostics handler that appends to the provided slice
+func collectDiagnostics(diags *[]Diagnostic) func(Diagnostic) {
+	var lock sync.Mutex
+	return func(d Diagnostic) {
+		lock.Lock()
+		*diags = append(*diags, d)
+		lock.Unlock()
+	}
+}
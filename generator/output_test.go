@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testGenOpts(specPath, target string) GenOpts {
+	return GenOpts{
+		Spec:          specPath,
+		APIPackage:    "operations",
+		ModelPackage:  "models",
+		ServerPackage: "restapi",
+		ClientPackage: "client",
+		Target:        target,
+	}
+}
+
+// withGopath runs fn with a temporary gopath, the target it gets is a directory inside it
+func withGopath(t *testing.T, fn func(target string)) {
+	gopath, err := ioutil.TempDir("", "generator")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(gopath)
+
+	old := os.Getenv("GOPATH")
+	os.Setenv("GOPATH", gopath)
+	defer os.Setenv("GOPATH", old)
+
+	fn(filepath.Join(gopath, "src", "example.com", "petstore"))
+}
+
+func generatedPaths(files []GeneratedFile) []string {
+	var result []string
+	for _, f := range files {
+		result = append(result, filepath.ToSlash(f.Path))
+	}
+	return result
+}
+
+func findGenerated(files []GeneratedFile, path string) (GeneratedFile, bool) {
+	for _, f := range files {
+		if filepath.ToSlash(f.Path) == path {
+			return f, true
+		}
+	}
+	return GeneratedFile{}, false
+}
+
+func TestMemFileSystem(t *testing.T) {
+	fs := NewMemFileSystem()
+	assert.False(t, fs.Exists("models/pet.go"))
+
+	content := []byte("package models")
+	assert.NoError(t, fs.WriteFile("models/pet.go", content))
+	assert.NoError(t, fs.WriteFile("./models/../api/api.go", []byte("package api")))
+	content[0] = 'P'
+
+	assert.True(t, fs.Exists("models/pet.go"))
+	assert.True(t, fs.Exists("api/api.go"))
+	files := fs.Files()
+	assert.Equal(t, []string{"api/api.go", "models/pet.go"}, generatedPaths(files))
+	assert.Equal(t, "package models", string(files[1].Content))
+}
+
+func TestDiagnosticString(t *testing.T) {
+	d := Diagnostic{Level: DiagnosticWarning, Message: "failed to format", Target: "models/pet.go", Err: os.ErrInvalid}
+	assert.Equal(t, "failed to format: models/pet.go (invalid argument)", d.String())
+	assert.Equal(t, "warning", d.Level.String())
+	assert.Equal(t, "error", DiagnosticError.String())
+	assert.Equal(t, "info", DiagnosticInfo.String())
+}
+
+func TestRenderModel(t *testing.T) {
+	files, diags, err := RenderModel(nil, true, true, testGenOpts("../fixtures/petstores/petstore.json", "petstore"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{
+		"petstore/models/error.go",
+		"petstore/models/error_validator.go",
+		"petstore/models/pet.go",
+		"petstore/models/pet_validator.go",
+	}, generatedPaths(files))
+
+	pet, _ := findGenerated(files, "petstore/models/pet.go")
+	assert.Contains(t, string(pet.Content), "package models")
+	assert.Contains(t, string(pet.Content), "type Pet struct {")
+
+	assert.NotEmpty(t, diags)
+	for _, d := range diags {
+		assert.Equal(t, DiagnosticInfo, d.Level, d.String())
+	}
+	_, err = os.Stat("petstore")
+	assert.True(t, os.IsNotExist(err), "nothing gets written to disk")
+}
+
+func TestRenderModelUnknown(t *testing.T) {
+	files, _, err := RenderModel([]string{"Unicorn"}, true, true, testGenOpts("../fixtures/petstores/petstore.json", "petstore"))
+	assert.Error(t, err)
+	assert.Empty(t, files)
+}
+
+func TestRenderServerOperation(t *testing.T) {
+	withGopath(t, func(target string) {
+		opts := testGenOpts("../fixtures/petstores/petstore-expanded.json", target)
+		files, _, err := RenderServerOperation([]string{"findPets"}, nil, true, true, opts)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if assert.Len(t, files, 2) {
+			handler := string(files[0].Content)
+			// the handler goes in the package that the builder imports as example.com/petstore/restapi/operations
+			assert.Equal(t, filepath.Join(target, "restapi", "operations", "find_pets.go"), files[0].Path)
+			assert.Contains(t, handler, `"example.com/petstore/models"`)
+			assert.Equal(t, filepath.Join(target, "restapi", "operations", "find_pets_parameters.go"), files[1].Path)
+		}
+	})
+}
+
+func TestRenderSupport(t *testing.T) {
+	withGopath(t, func(target string) {
+		opts := testGenOpts("../fixtures/petstores/petstore-expanded.json", target)
+		files, _, err := RenderSupport("", nil, nil, false, opts)
+		if !assert.NoError(t, err) {
+			return
+		}
+		main, ok := findGenerated(files, filepath.ToSlash(filepath.Join(target, "cmd", "swagger-petstore-server", "main.go")))
+		if assert.True(t, ok, "files: %v", generatedPaths(files)) {
+			assert.Contains(t, string(main.Content), `"example.com/petstore/restapi/operations"`)
+		}
+	})
+}
+
+func TestRenderSupportOutsideGopath(t *testing.T) {
+	withGopath(t, func(string) {
+		opts := testGenOpts("../fixtures/petstores/petstore.json", os.TempDir())
+		files, _, err := RenderSupport("", nil, nil, false, opts)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "gopath")
+		}
+		assert.Empty(t, files)
+
+		_, err = SupportData("", nil, nil, false, opts)
+		assert.Error(t, err)
+	})
+}
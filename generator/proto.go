@@ -0,0 +1,464 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/go-swagger/go-swagger/swag"
+)
+
+var protoTemplate *template.Template
+
+func init() {
+	bp, _ := Asset("templates/proto/proto.gotmpl")
+	protoTemplate = template.Must(template.New("proto").Parse(string(bp)))
+}
+
+// the vendor extension that holds the field number of a property in the protobuf message
+const xProtoField = "x-proto-field"
+
+// the well known protobuf types used for formats and values that don't have a scalar type
+var protoWellKnownTypes = map[string]string{
+	"google.protobuf.Timestamp": "google/protobuf/timestamp.proto",
+	"google.protobuf.Duration":  "google/protobuf/duration.proto",
+	"google.protobuf.Value":     "google/protobuf/struct.proto",
+}
+
+// GenerateProto generates a proto3 file with a message for every definition of a spec.
+// The field numbers are read from the x-proto-field extension of the properties,
+// properties without one are numbered after the highest number in use and reported.
+func GenerateProto(name string, modelNames []string, opts GenOpts) error {
+	_, specDoc, err := loadSpec(opts)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		if specDoc.Spec().Info != nil && specDoc.Spec().Info.Title != "" {
+			name = specDoc.Spec().Info.Title
+		} else {
+			name = "swagger"
+		}
+	}
+
+	generator := protoGenerator{
+		Package:    strings.Replace(swag.ToFileName(name), "-", "_", -1),
+		SpecDoc:    specDoc,
+		ModelNames: modelNames,
+		Target:     opts.Target,
+		DumpData:   opts.DumpData,
+		out:        newOutput(opts),
+	}
+	return generator.Generate()
+}
+
+type protoGenerator struct {
+	Package    string
+	SpecDoc    *spec.Document
+	ModelNames []string
+	Target     string
+	DumpData   bool
+	out        *output
+}
+
+func (p *protoGenerator) Generate() error {
+	proto, err := p.makeCodegenProto()
+	if err != nil {
+		return err
+	}
+
+	if p.DumpData {
+		bb, _ := json.MarshalIndent(swag.ToDynamicJSON(proto), "", "  ")
+		fmt.Fprintln(os.Stdout, string(bb))
+		return nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := protoTemplate.Execute(buf, proto); err != nil {
+		return err
+	}
+	p.out.Info("rendered proto template", proto.Package)
+	return p.out.writeFile(filepath.Join(p.Target, "proto"), proto.Package+".proto", buf.Bytes())
+}
+
+type genProto struct {
+	Package  string
+	Imports  []string
+	Messages []genProtoMessage
+}
+
+type genProtoMessage struct {
+	Name    string
+	Comment []string
+	Enums   []genProtoEnum
+	Fields  []genProtoField
+}
+
+type genProtoEnum struct {
+	Name   string
+	Values []genProtoEnumValue
+}
+
+type genProtoEnumValue struct {
+	Name   string
+	Number int
+}
+
+type genProtoField struct {
+	Name    string
+	Type    string
+	Number  int
+	Comment []string
+}
+
+func (p *protoGenerator) makeCodegenProto() (genProto, error) {
+	var names []string
+	for k := range p.SpecDoc.Spec().Definitions {
+		if p.includes(k) {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	b := protoBuilder{gen: p, imports: make(map[string]bool)}
+	for _, k := range names {
+		schema := p.SpecDoc.Spec().Definitions[k]
+		if err := b.addMessage(k, swag.ToGoName(k), schema); err != nil {
+			return genProto{}, err
+		}
+	}
+
+	res := genProto{Package: p.Package, Messages: b.messages}
+	for k := range b.imports {
+		res.Imports = append(res.Imports, k)
+	}
+	sort.Strings(res.Imports)
+	return res, nil
+}
+
+func (p *protoGenerator) includes(name string) bool {
+	if len(p.ModelNames) == 0 {
+		return true
+	}
+	for _, nm := range p.ModelNames {
+		if nm == name {
+			return true
+		}
+	}
+	return false
+}
+
+// protoBuilder collects the messages for the definitions, inline objects become messages of their own
+type protoBuilder struct {
+	gen      *protoGenerator
+	imports  map[string]bool
+	messages []genProtoMessage
+}
+
+func (b *protoBuilder) warn(target, message string) {
+	b.gen.out.Warn(message, target, nil)
+}
+
+// addMessage adds the message for an object schema, path is used to report the constructs that don't map cleanly
+func (b *protoBuilder) addMessage(path, name string, schema spec.Schema) error {
+	msg := genProtoMessage{Name: name, Comment: protoComment(schema.Description)}
+
+	if !schema.Type.Contains("object") && len(schema.Type) > 0 {
+		b.warn(path, "only objects can be turned into a message, wrapping the value in a value field")
+		value := schema
+		value.Description = ""
+		schema = spec.Schema{}
+		schema.Properties = map[string]spec.Schema{"value": value}
+	} else if schema.AdditionalProperties != nil {
+		if len(schema.Properties) > 0 {
+			b.warn(path, "a message can't have additional properties next to its fields, ignoring them")
+		} else {
+			b.warn(path, "a message can't have additional properties, they are kept in a map field named values")
+			values := schema
+			values.Description = ""
+			schema.Properties = map[string]spec.Schema{"values": values}
+		}
+	}
+
+	props, err := b.properties(path, schema)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for k := range props {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	used := make(map[int]string)
+	for _, k := range names {
+		num, ok, err := protoFieldNumber(props[k])
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", path, k, err)
+		}
+		if !ok {
+			continue
+		}
+		if other, dup := used[num]; dup {
+			return fmt.Errorf("%s: properties %s and %s have the same %s %d", path, other, k, xProtoField, num)
+		}
+		used[num] = k
+	}
+
+	next := 1
+	for num := range used {
+		if num >= next {
+			next = num + 1
+		}
+	}
+
+	for _, k := range names {
+		prop := props[k]
+		tpe, enum, ok, err := b.fieldType(path+"."+k, name, k, prop)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if enum != nil {
+			msg.Enums = append(msg.Enums, *enum)
+		}
+
+		num, hasNum, _ := protoFieldNumber(prop)
+		if !hasNum {
+			num = next
+			if num >= 19000 && num <= 19999 {
+				num = 20000
+			}
+			next = num + 1
+			b.warn(path+"."+k, fmt.Sprintf("no %s extension, using field number %d; add the extension to keep the number stable", xProtoField, num))
+		}
+		msg.Fields = append(msg.Fields, genProtoField{
+			Name:    protoFieldName(k),
+			Type:    tpe,
+			Number:  num,
+			Comment: protoComment(prop.Description),
+		})
+	}
+	sort.Sort(genProtoFieldSlice(msg.Fields))
+
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+// properties returns the properties of an object, the properties of the schemas it is composed of are merged in
+func (b *protoBuilder) properties(path string, schema spec.Schema) (map[string]spec.Schema, error) {
+	res := make(map[string]spec.Schema)
+	for k, v := range schema.Properties {
+		res[k] = v
+	}
+	if len(schema.AllOf) > 0 {
+		b.warn(path, "protobuf has no composition, the properties of allOf are merged into the message")
+	}
+	for _, s := range schema.AllOf {
+		if ref := s.Ref.GetURL(); ref != nil {
+			def, ok := b.gen.SpecDoc.Spec().Definitions[filepath.Base(ref.Fragment)]
+			if !ok {
+				return nil, fmt.Errorf("%s: can't resolve %s", path, ref.String())
+			}
+			s = def
+		}
+		props, err := b.properties(path, s)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range props {
+			if _, ok := res[k]; ok {
+				b.warn(path+"."+k, "property is defined more than once in allOf, keeping the first one")
+				continue
+			}
+			res[k] = v
+		}
+	}
+	return res, nil
+}
+
+// fieldType returns the protobuf type of a property, false when the property can't be represented
+func (b *protoBuilder) fieldType(path, message, prop string, schema spec.Schema) (string, *genProtoEnum, bool, error) {
+	if ref := schema.Ref.GetURL(); ref != nil {
+		return swag.ToGoName(filepath.Base(ref.Fragment)), nil, true, nil
+	}
+
+	if schema.Type.Contains("array") {
+		if schema.Items == nil || schema.Items.Schema == nil {
+			b.warn(path, "arrays need a single items schema to become a repeated field, skipping it")
+			return "", nil, false, nil
+		}
+		item := *schema.Items.Schema
+		if item.Type.Contains("array") || (item.AdditionalProperties != nil && item.Ref.GetURL() == nil) {
+			b.warn(path, "repeated fields can't contain arrays or maps, skipping it")
+			return "", nil, false, nil
+		}
+		tpe, enum, ok, err := b.fieldType(path, message, prop, item)
+		if !ok || err != nil {
+			return "", nil, ok, err
+		}
+		return "repeated " + tpe, enum, true, nil
+	}
+
+	if schema.AdditionalProperties != nil {
+		if schema.AdditionalProperties.Schema == nil {
+			b.warn(path, "maps need a schema for their values, using google.protobuf.Value")
+			b.imports[protoWellKnownTypes["google.protobuf.Value"]] = true
+			return "map<string, google.protobuf.Value>", nil, true, nil
+		}
+		value := *schema.AdditionalProperties.Schema
+		if value.Type.Contains("array") || (value.AdditionalProperties != nil && value.Ref.GetURL() == nil) {
+			b.warn(path, "map values can't be arrays or maps, skipping it")
+			return "", nil, false, nil
+		}
+		tpe, enum, ok, err := b.fieldType(path, message, prop, value)
+		if !ok || err != nil {
+			return "", nil, ok, err
+		}
+		return "map<string, " + tpe + ">", enum, true, nil
+	}
+
+	if schema.Type.Contains("object") || (len(schema.Type) == 0 && len(schema.Properties) > 0) {
+		name := message + swag.ToGoName(prop)
+		if err := b.addMessage(path, name, schema); err != nil {
+			return "", nil, false, err
+		}
+		return name, nil, true, nil
+	}
+
+	if len(schema.Enum) > 0 {
+		if !schema.Type.Contains("string") {
+			b.warn(path, "only string enums can be turned into a protobuf enum, using the type of the values")
+		} else {
+			enum := protoEnum(swag.ToGoName(prop), schema.Enum)
+			return enum.Name, &enum, true, nil
+		}
+	}
+
+	if len(schema.Type) != 1 {
+		b.warn(path, "the type can't be mapped to a scalar type, using google.protobuf.Value")
+		b.imports[protoWellKnownTypes["google.protobuf.Value"]] = true
+		return "google.protobuf.Value", nil, true, nil
+	}
+
+	tpe := protoScalarType(schema.Type[0], schema.Format)
+	if tpe == "" {
+		b.warn(path, fmt.Sprintf("type %s with format %s has no protobuf type, using string", schema.Type[0], schema.Format))
+		return "string", nil, true, nil
+	}
+	if imp, ok := protoWellKnownTypes[tpe]; ok {
+		b.imports[imp] = true
+	}
+	if schema.Format == "date" {
+		b.warn(path, "there is no well known type for dates, using string")
+	}
+	return tpe, nil, true, nil
+}
+
+// protoScalarType maps a json schema type and format to a protobuf type, the formats
+// that represent time use the well known types
+func protoScalarType(tpe, format string) string {
+	switch tpe {
+	case "string":
+		switch format {
+		case "date-time":
+			return "google.protobuf.Timestamp"
+		case "duration":
+			return "google.protobuf.Duration"
+		case "byte", "binary":
+			return "bytes"
+		}
+		return "string"
+	case "integer":
+		switch format {
+		case "int32", "int8", "int16":
+			return "int32"
+		case "uint32", "uint8", "uint16":
+			return "uint32"
+		case "uint64":
+			return "uint64"
+		}
+		return "int64"
+	case "number":
+		if format == "float" {
+			return "float"
+		}
+		return "double"
+	case "boolean":
+		return "bool"
+	}
+	return ""
+}
+
+// protoEnum creates an enum with a zero value for unspecified, the values are prefixed with the
+// name of the enum because enum values share the scope of the message they're in
+func protoEnum(name string, values []interface{}) genProtoEnum {
+	prefix := strings.ToUpper(swag.ToFileName(name))
+	enum := genProtoEnum{
+		Name:   name,
+		Values: []genProtoEnumValue{{Name: prefix + "_UNSPECIFIED", Number: 0}},
+	}
+	for i, v := range values {
+		enum.Values = append(enum.Values, genProtoEnumValue{
+			Name:   prefix + "_" + strings.ToUpper(swag.ToFileName(fmt.Sprint(v))),
+			Number: i + 1,
+		})
+	}
+	return enum
+}
+
+// protoFieldNumber reads the field number from the x-proto-field extension
+func protoFieldNumber(schema spec.Schema) (int, bool, error) {
+	v, ok := schema.Extensions[xProtoField]
+	if !ok {
+		return 0, false, nil
+	}
+	var num int
+	switch n := v.(type) {
+	case float64:
+		num = int(n)
+		if float64(num) != n {
+			return 0, false, fmt.Errorf("%s must be an integer, got %v", xProtoField, v)
+		}
+	case int:
+		num = n
+	case int64:
+		num = int(n)
+	default:
+		return 0, false, fmt.Errorf("%s must be an integer, got %v", xProtoField, v)
+	}
+	if num < 1 || num > 536870911 || (num >= 19000 && num <= 19999) {
+		return 0, false, fmt.Errorf("%s %d is not a valid field number", xProtoField, num)
+	}
+	return num, true, nil
+}
+
+func protoFieldName(name string) string {
+	return strings.Replace(swag.ToFileName(name), "-", "_", -1)
+}
+
+func protoComment(text string) []string {
+	var res []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+type genProtoFieldSlice []genProtoField
+
+func (g genProtoFieldSlice) Len() int           { return len(g) }
+func (g genProtoFieldSlice) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g genProtoFieldSlice) Less(i, j int) bool { return g[i].Number < g[j].Number }
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtoFieldNumbers(t *testing.T) {
+	opts := testGenOpts("../fixtures/codegen/proto.yml", "api")
+	fs := NewMemFileSystem()
+	var diags []Diagnostic
+	opts.FileSystem = fs
+	opts.Diagnostics = collectDiagnostics(&diags)
+	if !assert.NoError(t, GenerateProto("", []string{"pet"}, opts)) {
+		return
+	}
+
+	files := fs.Files()
+	if !assert.Equal(t, []string{"api/proto/pet_store.proto"}, generatedPaths(files)) {
+		return
+	}
+	expected := `// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+syntax = "proto3";
+
+package pet_store;
+
+import "google/protobuf/timestamp.proto";
+
+// a pet in the store
+message Pet {
+  enum Kind {
+    KIND_UNSPECIFIED = 0;
+    KIND_CAT = 1;
+    KIND_DOG = 2;
+  }
+
+  int64 id = 1;
+  Kind kind = 2;
+  google.protobuf.Timestamp born = 3;
+  // the name of the pet
+  string name = 7;
+  string nickname = 8;
+  string tag = 9;
+}
+
+`
+	assert.Equal(t, expected, string(files[0].Content))
+
+	// the properties without a number get one after the highest number in use, with a warning
+	var warned []string
+	for _, d := range diags {
+		if d.Level == DiagnosticWarning {
+			warned = append(warned, d.Target)
+		}
+	}
+	assert.Equal(t, []string{"pet.nickname", "pet.tag"}, warned)
+}
+
+func TestProtoDuplicateFieldNumber(t *testing.T) {
+	opts := testGenOpts("../fixtures/codegen/proto.yml", "api")
+	opts.FileSystem = NewMemFileSystem()
+	opts.Diagnostics = func(Diagnostic) {}
+	err := GenerateProto("", []string{"clash"}, opts)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "properties first and second have the same x-proto-field 1")
+	}
+}
+
+func TestProtoFieldNumber(t *testing.T) {
+	schema := func(v interface{}) spec.Schema {
+		var s spec.Schema
+		if v != nil {
+			s.AddExtension(xProtoField, v)
+		}
+		return s
+	}
+
+	num, ok, err := protoFieldNumber(schema(float64(12)))
+	if assert.NoError(t, err) && assert.True(t, ok) {
+		assert.Equal(t, 12, num)
+	}
+	_, ok, err = protoFieldNumber(schema(nil))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, v := range []interface{}{float64(1.5), "1", float64(0), float64(19000), float64(536870912)} {
+		_, _, err := protoFieldNumber(schema(v))
+		assert.Error(t, err, "value: %v", v)
+	}
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeCodegenServices(t *testing.T) {
+	operations := []genOperation{
+		{Name: "listPets", ClassName: "ListPets", Package: "pets", Imports: map[string]string{"models": "example.com/api/models"}},
+		{Name: "health", ClassName: "Health", Package: ""},
+		{Name: "getUser", ClassName: "GetUser", Package: "users"},
+		{Name: "addPet", ClassName: "AddPet", Package: "pets"},
+		{Name: "version", ClassName: "Version", Package: "operations"},
+	}
+	services := makeCodegenServices("Services", "o", "operations", "example.com/api/models", operations)
+
+	var names []string
+	for _, svc := range services {
+		var ops []string
+		for _, op := range svc.Operations {
+			ops = append(ops, op.ClassName)
+		}
+		names = append(names, svc.ClassName+":"+svc.Package+":"+svc.Tag)
+		switch svc.ClassName {
+		case "PetsService":
+			assert.Equal(t, []string{"ListPets", "AddPet"}, ops)
+			assert.Equal(t, map[string]string{"models": "example.com/api/models"}, svc.Imports)
+		case "UsersService":
+			assert.Equal(t, []string{"GetUser"}, ops)
+		case "ServicesService":
+			// the operations without a tag and the ones in the api package end up in the same service
+			assert.Equal(t, []string{"Health", "Version"}, ops)
+		}
+		assert.Equal(t, []string{"example.com/api/models"}, svc.DefaultImports)
+	}
+	assert.Equal(t, []string{"PetsService:pets:pets", "ServicesService:operations:", "UsersService:users:users"}, names)
+}
+
+// interfaceMethods returns the names of the methods of the interfaces that are declared in a file
+func interfaceMethods(t *testing.T, path string) map[string][]string {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	result := make(map[string][]string)
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		if iface, ok := spec.Type.(*ast.InterfaceType); ok {
+			var methods []string
+			for _, m := range iface.Methods.List {
+				for _, name := range m.Names {
+					methods = append(methods, name.Name)
+				}
+			}
+			sort.Strings(methods)
+			result[spec.Name.Name] = methods
+		}
+		return false
+	})
+	return result
+}
+
+// servicesCheck implements the generated services and checks that registering them sets every handler of the api
+const servicesCheck = `package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/go-swagger/go-swagger/generator/{{dir}}/models"
+	"github.com/go-swagger/go-swagger/generator/{{dir}}/restapi/operations"
+	"github.com/go-swagger/go-swagger/generator/{{dir}}/restapi/operations/pets"
+	"github.com/go-swagger/go-swagger/generator/{{dir}}/restapi/operations/users"
+)
+
+type service struct{}
+
+func (service) ListPets() ([]models.Pet, error)  { return nil, nil }
+func (service) AddPet(pets.AddPetParams, *models.User) error            { return nil }
+func (service) GetUser(users.GetUserParams) (*models.User, error) { return nil, nil }
+func (service) Health() error { return nil }
+
+func main() {
+	api := new(operations.ServicesAPI)
+	api.RegisterPetsService(service{})
+	api.RegisterUsersService(service{})
+	api.RegisterServicesService(service{})
+
+	var missing []string
+	value := reflect.ValueOf(api).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Name
+		if strings.HasSuffix(name, "Handler") && value.Field(i).IsNil() {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Println("not registered:", strings.Join(missing, ", "))
+		os.Exit(1)
+	}
+}
+`
+
+func TestServicesCompile(t *testing.T) {
+	opts := testGenOpts("../fixtures/codegen/services.yml", "")
+	opts.Services = true
+	opts.Principal = "models.User"
+	generateServer(t, opts, nil, func(dir string) {
+		services := make(map[string][]string)
+		for _, file := range []string{"services_service.go", "pets/pets_service.go", "users/users_service.go"} {
+			for k, v := range interfaceMethods(t, filepath.Join(dir, "restapi", "operations", file)) {
+				services[k] = v
+			}
+		}
+		assert.Equal(t, map[string][]string{
+			"PetsService":     {"AddPet", "ListPets"},
+			"UsersService":    {"GetUser"},
+			"ServicesService": {"Health"},
+		}, services)
+
+		out, err := runGenerated(dir, servicesCheck)
+		assert.NoError(t, err, out)
+	})
+}
@@ -2,10 +2,7 @@ package generator
 
 import (
 	"fmt"
-	"io/ioutil"
-	"log"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/go-swagger/go-swagger/spec"
@@ -54,6 +51,21 @@ type GenOpts struct {
 	TypeMapping   map[string]string
 	Imports       map[string]string
 	DumpData      bool
+	// Concurrency is the maximum number of models or operations rendered at the same time,
+	// when 0 it defaults to the number of CPUs
+	Concurrency int
+	// FileSystem receives the generated files, when nil the files are written to disk
+	FileSystem FileSystem
+	// Diagnostics receives the messages reported while generating, when nil they are logged
+	Diagnostics func(Diagnostic)
+	// FormatMappings the go types to use for custom string formats
+	FormatMappings []FormatMapping
+	// Services generates an interface per tag with a method for every operation of that tag
+	Services bool
+	// ModelHelpers the optional equality, deep copy and constructor helpers to generate for every model
+	ModelHelpers ModelHelpers
+	// TemplateDir a directory with templates that override the builtin ones
+	TemplateDir string
 }
 
 type generatorOptions struct {
@@ -116,9 +128,9 @@ type genValidations struct {
 	NeedsSize           bool    //`json:"needsSize,omitempty"`
 }
 
-func loadSpec(specFile string) (string, *spec.Document, error) {
+func loadSpec(opts GenOpts) (string, *spec.Document, error) {
 	// find swagger spec document, verify it exists
-	specPath, err := findSwaggerSpec(specFile)
+	specPath, err := findSwaggerSpec(opts.Spec)
 	if err != nil {
 		return "", nil, err
 	}
@@ -131,17 +143,8 @@ func loadSpec(specFile string) (string, *spec.Document, error) {
 	return specPath, specDoc, nil
 }
 
-func fileExists(target, name string) bool {
-	ffn := swag.ToFileName(name) + ".go"
-	_, err := os.Stat(filepath.Join(target, ffn))
-	return !os.IsNotExist(err)
-}
-
-func writeToFileIfNotExist(target, name string, content []byte) error {
-	if fileExists(target, name) {
-		return nil
-	}
-	return writeToFile(target, name, content)
+func goFileName(name string) string {
+	return swag.ToFileName(name) + ".go"
 }
 
 func formatGoFile(ffn string, content []byte) ([]byte, error) {
@@ -154,25 +157,6 @@ func formatGoFile(ffn string, content []byte) ([]byte, error) {
 	return imports.Process(ffn, content, opts)
 }
 
-func writeToFile(target, name string, content []byte) error {
-	ffn := swag.ToFileName(name) + ".go"
-	res, err := formatGoFile(ffn, content)
-	if err != nil {
-		log.Println(err)
-		return writeFile(target, ffn, content)
-	}
-
-	return writeFile(target, ffn, res)
-}
-
-func writeFile(target, ffn string, content []byte) error {
-	if err := os.MkdirAll(target, 0755); err != nil {
-		return err
-	}
-
-	return ioutil.WriteFile(filepath.Join(target, ffn), content, 0644)
-}
-
 func commentedLines(str string) string {
 	lines := strings.Split(str, "\n")
 	var commented []string
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -18,6 +18,7 @@ var (
 	builderTemplate      *template.Template
 	mainTemplate         *template.Template
 	configureAPITemplate *template.Template
+	serviceTemplate      *template.Template
 )
 
 func init() {
@@ -29,15 +30,56 @@ func init() {
 
 	bc, _ := Asset("templates/server/configureapi.gotmpl")
 	configureAPITemplate = template.Must(template.New("configureapi").Parse(string(bc)))
+
+	bs, _ := Asset("templates/server/service.gotmpl")
+	serviceTemplate = template.Must(template.New("service").Parse(string(bs)))
 }
 
 // GenerateSupport generates the supporting files for an API
 func GenerateSupport(name string, modelNames, operationIDs []string, includeUI bool, opts GenOpts) error {
-	// Load the spec
-	_, specDoc, err := loadSpec(opts.Spec)
+	generator, err := newAppGenerator(name, modelNames, operationIDs, includeUI, opts)
 	if err != nil {
 		return err
 	}
+	return generator.Generate()
+}
+
+// RenderSupport renders the supporting files for an API in memory.
+// The file system and diagnostics handler in the options are replaced to collect the results.
+func RenderSupport(name string, modelNames, operationIDs []string, includeUI bool, opts GenOpts) ([]GeneratedFile, []Diagnostic, error) {
+	fs := NewMemFileSystem()
+	var diags []Diagnostic
+	opts.FileSystem = fs
+	opts.Diagnostics = collectDiagnostics(&diags)
+	opts.DumpData = false
+
+	err := GenerateSupport(name, modelNames, operationIDs, includeUI, opts)
+	return fs.Files(), diags, err
+}
+
+// SupportData returns the data the supporting templates get rendered with, this is what --dump-data prints
+func SupportData(name string, modelNames, operationIDs []string, includeUI bool, opts GenOpts) (interface{}, error) {
+	generator, err := newAppGenerator(name, modelNames, operationIDs, includeUI, opts)
+	if err != nil {
+		return nil, err
+	}
+	app, err := generator.makeCodegenApp()
+	if err != nil {
+		return nil, err
+	}
+	return swag.ToDynamicJSON(app), nil
+}
+
+func newAppGenerator(name string, modelNames, operationIDs []string, includeUI bool, opts GenOpts) (*appGenerator, error) {
+	// Load the spec
+	_, specDoc, err := loadSpec(opts)
+	if err != nil {
+		return nil, err
+	}
+	types, err := newTypeResolver(opts.FormatMappings)
+	if err != nil {
+		return nil, err
+	}
 
 	models, mnc := make(map[string]spec.Schema), len(modelNames)
 	for k, v := range specDoc.Spec().Definitions {
@@ -75,9 +117,10 @@ func GenerateSupport(name string, modelNames, operationIDs []string, includeUI b
 		}
 	}
 
-	generator := appGenerator{
+	return &appGenerator{
 		Name:       name,
 		SpecDoc:    specDoc,
+		Types:      types,
 		Models:     models,
 		Operations: operations,
 		Target:     opts.Target,
@@ -90,14 +133,15 @@ func GenerateSupport(name string, modelNames, operationIDs []string, includeUI b
 		ClientPackage: opts.ClientPackage,
 		Principal:     opts.Principal,
 		IncludeUI:     includeUI,
-	}
-
-	return generator.Generate()
+		Services:      opts.Services,
+		out:           newOutput(opts),
+	}, nil
 }
 
 type appGenerator struct {
 	Name          string
 	SpecDoc       *spec.Document
+	Types         *typeResolver
 	Package       string
 	APIPackage    string
 	ModelsPackage string
@@ -109,12 +153,15 @@ type appGenerator struct {
 	Target        string
 	DumpData      bool
 	IncludeUI     bool
+	Services      bool
+	out           *output
 }
 
-func baseImport(tgt string) string {
+// baseImport returns the import path of the target directory, the target needs to be inside the gopath
+func baseImport(tgt string) (string, error) {
 	p, err := filepath.Abs(tgt)
 	if err != nil {
-		log.Fatalln(err)
+		return "", err
 	}
 
 	var pth string
@@ -123,20 +170,23 @@ func baseImport(tgt string) string {
 		if strings.HasPrefix(p, pp) {
 			pth, err = filepath.Rel(pp, p)
 			if err != nil {
-				log.Fatalln(err)
+				return "", err
 			}
 			break
 		}
 	}
 
 	if pth == "" {
-		log.Fatalln("target must reside inside a location in the gopath")
+		return "", fmt.Errorf("target %s must reside inside a location in the gopath", tgt)
 	}
-	return pth
+	return pth, nil
 }
 
 func (a *appGenerator) Generate() error {
-	app := a.makeCodegenApp()
+	app, err := a.makeCodegenApp()
+	if err != nil {
+		return err
+	}
 
 	if a.DumpData {
 		bb, _ := json.MarshalIndent(swag.ToDynamicJSON(app), "", "  ")
@@ -147,13 +197,23 @@ func (a *appGenerator) Generate() error {
 	if err := a.generateAPIBuilder(&app); err != nil {
 		return err
 	}
-	importPath := filepath.ToSlash(filepath.Join(baseImport(a.Target), a.ServerPackage, a.APIPackage))
+	baseImp, err := baseImport(a.Target)
+	if err != nil {
+		return err
+	}
+	importPath := filepath.ToSlash(filepath.Join(baseImp, a.ServerPackage, a.APIPackage))
 	app.DefaultImports = append(app.DefaultImports, importPath)
 
 	if err := a.generateConfigureAPI(&app); err != nil {
 		return err
 	}
 
+	for _, service := range app.Services {
+		if err := a.generateService(service); err != nil {
+			return err
+		}
+	}
+
 	if err := a.generateMain(&app); err != nil {
 		return err
 	}
@@ -164,8 +224,8 @@ func (a *appGenerator) Generate() error {
 func (a *appGenerator) generateConfigureAPI(app *genApp) error {
 	pth := filepath.Join(a.Target, "cmd", swag.ToCommandName(app.AppName+"Server"))
 	nm := "Configure" + app.AppName
-	if fileExists(pth, nm) {
-		log.Println("skipped (already exists) configure api template:", app.Package+".Configure"+app.AppName)
+	if a.out.fileExists(pth, nm) {
+		a.out.Info("skipped (already exists) configure api template", app.Package+".Configure"+app.AppName)
 		return nil
 	}
 
@@ -173,8 +233,22 @@ func (a *appGenerator) generateConfigureAPI(app *genApp) error {
 	if err := configureAPITemplate.Execute(buf, app); err != nil {
 		return err
 	}
-	log.Println("rendered configure api template:", app.Package+".Configure"+app.AppName)
-	return writeToFileIfNotExist(pth, nm, buf.Bytes())
+	a.out.Info("rendered configure api template", app.Package+".Configure"+app.AppName)
+	return a.out.writeToFileIfNotExist(pth, nm, buf.Bytes())
+}
+
+func (a *appGenerator) generateService(service genService) error {
+	buf := bytes.NewBuffer(nil)
+	if err := serviceTemplate.Execute(buf, service); err != nil {
+		return err
+	}
+	a.out.Info("rendered service template", service.Package+"."+service.ClassName)
+
+	pth := filepath.Join(a.Target, a.ServerPackage, a.APIPackage)
+	if service.Tag != "" {
+		pth = filepath.Join(pth, service.Tag)
+	}
+	return a.out.writeToFile(pth, service.Name, buf.Bytes())
 }
 
 func (a *appGenerator) generateMain(app *genApp) error {
@@ -182,8 +256,8 @@ func (a *appGenerator) generateMain(app *genApp) error {
 	if err := mainTemplate.Execute(buf, app); err != nil {
 		return err
 	}
-	log.Println("rendered main template:", "server."+app.AppName)
-	return writeToFile(filepath.Join(a.Target, "cmd", swag.ToCommandName(app.AppName+"Server")), "main", buf.Bytes())
+	a.out.Info("rendered main template", "server."+app.AppName)
+	return a.out.writeToFile(filepath.Join(a.Target, "cmd", swag.ToCommandName(app.AppName+"Server")), "main", buf.Bytes())
 }
 
 func (a *appGenerator) generateAPIBuilder(app *genApp) error {
@@ -191,8 +265,8 @@ func (a *appGenerator) generateAPIBuilder(app *genApp) error {
 	if err := builderTemplate.Execute(buf, app); err != nil {
 		return err
 	}
-	log.Println("rendered builder template:", app.Package+"."+app.AppName)
-	return writeToFile(filepath.Join(a.Target, a.ServerPackage, app.Package), app.AppName+"Api", buf.Bytes())
+	a.out.Info("rendered builder template", app.Package+"."+app.AppName)
+	return a.out.writeToFile(filepath.Join(a.Target, a.ServerPackage, app.Package), app.AppName+"Api", buf.Bytes())
 }
 
 var mediaTypeNames = map[string]string{
@@ -232,8 +306,12 @@ func getSerializer(sers []genSerGroup, ext string) (*genSerGroup, bool) {
 }
 
 // func makeCodegenApp(operations map[string]spec.Operation, includeUI bool) genApp {
-func (a *appGenerator) makeCodegenApp() genApp {
+func (a *appGenerator) makeCodegenApp() (genApp, error) {
 	sw := a.SpecDoc.Spec()
+	baseImp, err := baseImport(a.Target)
+	if err != nil {
+		return genApp{}, err
+	}
 	// app := makeCodegenApp(a.Operations, a.IncludeUI)
 	receiver := strings.ToLower(a.Name[:1])
 	appName := swag.ToGoName(a.Name)
@@ -241,9 +319,11 @@ func (a *appGenerator) makeCodegenApp() genApp {
 
 	jsonb, _ := json.MarshalIndent(a.SpecDoc.Spec(), "", "  ")
 
+	rc := a.SpecDoc.RequiredConsumes()
+	sort.Strings(rc)
 	consumesJSON := false
 	var consumes []genSerGroup
-	for _, cons := range a.SpecDoc.RequiredConsumes() {
+	for _, cons := range rc {
 		cn, ok := mediaTypeNames[cons]
 		if !ok {
 			continue
@@ -288,9 +368,11 @@ func (a *appGenerator) makeCodegenApp() genApp {
 		})
 	}
 
+	rp := a.SpecDoc.RequiredProduces()
+	sort.Strings(rp)
 	producesJSON := false
 	var produces []genSerGroup
-	for _, prod := range a.SpecDoc.RequiredProduces() {
+	for _, prod := range rp {
 		pn, ok := mediaTypeNames[prod]
 		if !ok {
 			continue
@@ -353,10 +435,11 @@ func (a *appGenerator) makeCodegenApp() genApp {
 	}
 
 	var genMods []genModel
-	importPath := filepath.ToSlash(filepath.Join(baseImport(a.Target), a.ModelsPackage))
+	importPath := filepath.ToSlash(filepath.Join(baseImp, a.ModelsPackage))
 	defaultImports = append(defaultImports, importPath)
 	for mn, m := range a.Models {
 		mod := *makeCodegenModel(
+			a.Types,
 			mn,
 			a.ModelsPackage,
 			m,
@@ -365,6 +448,7 @@ func (a *appGenerator) makeCodegenApp() genApp {
 		mod.ReceiverName = receiver
 		genMods = append(genMods, mod)
 	}
+	sort.Sort(genModelSlice(genMods))
 
 	var genOps []genOperation
 	tns := make(map[string]struct{})
@@ -377,29 +461,46 @@ func (a *appGenerator) makeCodegenApp() genApp {
 		if len(o.Tags) > 0 {
 			for _, tag := range o.Tags {
 				tns[tag] = struct{}{}
-				op := makeCodegenOperation(on, tag, a.ModelsPackage, a.Principal, a.Target, o, authed)
+				op, err := makeCodegenOperation(a.Types, on, tag, a.ModelsPackage, a.Principal, a.Target, o, authed)
+				if err != nil {
+					return genApp{}, err
+				}
 				op.ReceiverName = receiver
 				genOps = append(genOps, op)
 			}
 		} else {
-			op := makeCodegenOperation(on, ap, a.ModelsPackage, a.Principal, a.Target, o, authed)
+			op, err := makeCodegenOperation(a.Types, on, ap, a.ModelsPackage, a.Principal, a.Target, o, authed)
+			if err != nil {
+				return genApp{}, err
+			}
 			op.ReceiverName = receiver
 			genOps = append(genOps, op)
 		}
 	}
+	sort.Sort(genOperationSlice(genOps))
+
+	var services []genService
+	if a.Services {
+		modelsImport := filepath.ToSlash(filepath.Join(baseImp, a.ModelsPackage))
+		services = makeCodegenServices(appName, receiver, a.Package, modelsImport, genOps)
+	}
+
+	var tags []string
 	for k := range tns {
-		importPath := filepath.ToSlash(filepath.Join(baseImport(a.Target), a.ServerPackage, a.APIPackage, k))
+		tags = append(tags, k)
+	}
+	sort.Strings(tags)
+	for _, k := range tags {
+		importPath := filepath.ToSlash(filepath.Join(baseImp, a.ServerPackage, a.APIPackage, k))
 		defaultImports = append(defaultImports, importPath)
 	}
 
 	defaultConsumes := "application/json"
-	rc := a.SpecDoc.RequiredConsumes()
 	if !consumesJSON && len(rc) > 0 {
 		defaultConsumes = rc[0]
 	}
 
 	defaultProduces := "application/json"
-	rp := a.SpecDoc.RequiredProduces()
 	if !producesJSON && len(rp) > 0 {
 		defaultProduces = rp[0]
 	}
@@ -420,10 +521,11 @@ func (a *appGenerator) makeCodegenApp() genApp {
 		SecurityDefinitions: security,
 		Models:              genMods,
 		Operations:          genOps,
+		Services:            services,
 		IncludeUI:           a.IncludeUI,
 		Principal:           a.Principal,
 		SwaggerJSON:         fmt.Sprintf("%#v", jsonb),
-	}
+	}, nil
 }
 
 type genApp struct {
@@ -444,10 +546,94 @@ type genApp struct {
 	SecurityDefinitions []genSecurityScheme
 	Models              []genModel
 	Operations          []genOperation
+	Services            []genService
 	IncludeUI           bool
 	SwaggerJSON         string
 }
 
+// genService groups the operations that share a tag in a single interface
+type genService struct {
+	Package        string
+	Tag            string
+	ReceiverName   string
+	AppName        string
+	ClassName      string
+	HumanClassName string
+	Name           string
+	Imports        map[string]string
+	DefaultImports []string
+	Operations     []genOperation
+}
+
+// makeCodegenServices creates a service for every tag, operations without a tag
+// end up in a service that is named after the application
+func makeCodegenServices(appName, receiver, pkg, modelsImport string, operations []genOperation) []genService {
+	var services []genService
+	byPackage := make(map[string]int)
+	for _, op := range operations {
+		// the operations without a tag are in the api package, whether they name it or not
+		servicePkg := op.Package
+		if servicePkg == "" {
+			servicePkg = pkg
+		}
+		idx, ok := byPackage[servicePkg]
+		if !ok {
+			name := appName
+			tag := ""
+			if servicePkg != pkg {
+				name = servicePkg
+				tag = servicePkg
+			}
+			services = append(services, genService{
+				Package:        servicePkg,
+				Tag:            tag,
+				ReceiverName:   receiver,
+				AppName:        appName,
+				ClassName:      swag.ToGoName(name) + "Service",
+				HumanClassName: swag.ToHumanNameLower(name),
+				Name:           swag.ToJSONName(name) + "Service",
+				DefaultImports: []string{modelsImport},
+			})
+			idx = len(services) - 1
+			byPackage[servicePkg] = idx
+		}
+
+		svc := &services[idx]
+		svc.Operations = append(svc.Operations, op)
+		for k, v := range op.Imports {
+			if svc.Imports == nil {
+				svc.Imports = make(map[string]string)
+			}
+			svc.Imports[k] = v
+		}
+	}
+	sort.Sort(genServiceSlice(services))
+	return services
+}
+
+type genServiceSlice []genService
+
+func (g genServiceSlice) Len() int           { return len(g) }
+func (g genServiceSlice) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g genServiceSlice) Less(i, j int) bool { return g[i].ClassName < g[j].ClassName }
+
+type genModelSlice []genModel
+
+func (g genModelSlice) Len() int           { return len(g) }
+func (g genModelSlice) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g genModelSlice) Less(i, j int) bool { return g[i].Name < g[j].Name }
+
+type genOperationSlice []genOperation
+
+func (g genOperationSlice) Len() int      { return len(g) }
+func (g genOperationSlice) Swap(i, j int) { g[i], g[j] = g[j], g[i] }
+func (g genOperationSlice) Less(i, j int) bool {
+	if g[i].Name == g[j].Name {
+		return g[i].Package < g[j].Package
+	}
+	return g[i].Name < g[j].Name
+}
+
 type genSerGroup struct {
 	ReceiverName   string
 	AppName        string
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -35,11 +34,15 @@ func init() {
 // GenerateSupport generates the supporting files for an API
 func GenerateTestSupport(name string, modelNames, operationIDs []string, includeUI bool,includeTCK bool, opts GenOpts) error {
 		// Load the spec
-	_, specDoc, err := loadSpec(opts.Spec)
+	_, specDoc, err := loadSpec(opts)
 	
 	if err != nil {
 		return err
 	}
+	types, err := newTypeResolver(opts.FormatMappings)
+	if err != nil {
+		return err
+	}
 	
 	
 	models, mnc := make(map[string]spec.Schema), len(modelNames)
@@ -81,6 +84,7 @@ func GenerateTestSupport(name string, modelNames, operationIDs []string, include
 	generator := testGenerator{
 		Name:          name,
 		SpecDoc:       specDoc,
+		Types:         types,
 		Models:        models,
 		Operations:    operations,
 		Target:        opts.Target,
@@ -93,6 +97,7 @@ func GenerateTestSupport(name string, modelNames, operationIDs []string, include
 		Principal:     opts.Principal,
 		IncludeUI:     includeUI,
 		IncludeTCK:    includeTCK,
+		out:           newOutput(opts),
 	}
 
 	return generator.GenerateTest()
@@ -101,6 +106,7 @@ func GenerateTestSupport(name string, modelNames, operationIDs []string, include
 type testGenerator struct {
 	Name          string
 	SpecDoc       *spec.Document
+	Types         *typeResolver
 	Package       string
 	APIPackage    string
 	ModelsPackage string
@@ -114,11 +120,15 @@ type testGenerator struct {
 	DumpData      bool
 	IncludeUI     bool
 	IncludeTCK	  bool
+	out           *output
 }
 
 
 func (t *testGenerator) GenerateTest() error {
-	test := t.makeCodegenTest()
+	test, err := t.makeCodegenTest()
+	if err != nil {
+		return err
+	}
 
 	if t.DumpData {
 		bb, _ := json.MarshalIndent(swag.ToDynamicJSON(test), "", "  ")
@@ -126,7 +136,11 @@ func (t *testGenerator) GenerateTest() error {
 		return nil
 	}
 
-	test.DefaultImports = append(test.DefaultImports, filepath.Join(baseImport(t.Target), t.ServerPackage, t.APIPackage))
+	baseImp, err := baseImport(t.Target)
+	if err != nil {
+		return err
+	}
+	test.DefaultImports = append(test.DefaultImports, filepath.Join(baseImp, t.ServerPackage, t.APIPackage))
 
 
 	if t.IncludeTCK {
@@ -155,8 +169,8 @@ func (t *testGenerator) generateSuiteTest(test *genTest) error {
 	if err := suiteTestTemplate.Execute(buf, test); err != nil {
 		return err
 	}
-	log.Println("rendered suite test template:", test.Package + test.AppName + "_suite_test")
-	return writeToFileIfNotExist(pth, nm, buf.Bytes())
+	t.out.Info("rendered suite test template", test.Package + test.AppName + "_suite_test")
+	return t.out.writeToFileIfNotExist(pth, nm, buf.Bytes())
 }
 
 func (t *testGenerator) generateTCK(test *genTest) error {
@@ -166,8 +180,8 @@ func (t *testGenerator) generateTCK(test *genTest) error {
 	if err := tckTemplate.Execute(buf, test); err != nil {
 		return err
 	}
-	log.Println("rendered tck template:", test.Package + "tck_reporter")
-	return writeToFileIfNotExist(pth, nm, buf.Bytes())
+	t.out.Info("rendered tck template", test.Package + "tck_reporter")
+	return t.out.writeToFileIfNotExist(pth, nm, buf.Bytes())
 }
 
 func (t *testGenerator) generateTest(test *genOperation, genT *genTest) error {
@@ -183,14 +197,14 @@ func (t *testGenerator) generateTest(test *genOperation, genT *genTest) error {
 	if err := testTemplate.Execute(buf, test); err != nil {
 		return err
 	}
-	log.Println("rendered test template:", test.Name)
-	return writeToFile(filepath.Join(t.Target, "cmd", swag.ToCommandName(genT.AppName)), test.Name + "_test", buf.Bytes())
+	t.out.Info("rendered test template", test.Name)
+	return t.out.writeToFile(filepath.Join(t.Target, "cmd", swag.ToCommandName(genT.AppName)), test.Name + "_test", buf.Bytes())
 }
 
 
 
 
-func (t *testGenerator) makeCodegenTest() genTest {
+func (t *testGenerator) makeCodegenTest() (genTest, error) {
 	sw := t.SpecDoc.Spec()
 	// app := makeCodegenApp(t.Operations, t.IncludeUI)
 	receiver := strings.ToLower(t.Name[:1])
@@ -311,9 +325,14 @@ func (t *testGenerator) makeCodegenTest() genTest {
 	}
 
 	var genMods []genModel
-	defaultImports = append(defaultImports, filepath.Join(baseImport(t.Target), t.ModelsPackage))
+	baseImp, err := baseImport(t.Target)
+	if err != nil {
+		return genTest{}, err
+	}
+	defaultImports = append(defaultImports, filepath.Join(baseImp, t.ModelsPackage))
 	for mn, m := range t.Models {
 		mod := *makeCodegenModel(
+			t.Types,
 			mn,
 			t.ModelsPackage,
 			m,
@@ -336,18 +355,24 @@ func (t *testGenerator) makeCodegenTest() genTest {
 		if len(o.Tags) > 0 {
 			for _, tag := range o.Tags {
 				tns[tag] = struct{}{}
-				op := makeCodegenOperation(on, tag, t.ModelsPackage, t.Principal, t.Target, o, authed)
+				op, err := makeCodegenOperation(t.Types, on, tag, t.ModelsPackage, t.Principal, t.Target, o, authed)
+				if err != nil {
+					return genTest{}, err
+				}
 				op.ReceiverName = receiver
 				genOps = append(genOps, op)
 			}
 		} else {
-			op := makeCodegenOperation(on, ap, t.ModelsPackage, t.Principal, t.Target, o, authed)
+			op, err := makeCodegenOperation(t.Types, on, ap, t.ModelsPackage, t.Principal, t.Target, o, authed)
+			if err != nil {
+				return genTest{}, err
+			}
 			op.ReceiverName = receiver
 			genOps = append(genOps, op)
 		}
 	}
 	for k := range tns {
-		defaultImports = append(defaultImports, filepath.Join(baseImport(t.Target), t.ServerPackage, t.APIPackage, k))
+		defaultImports = append(defaultImports, filepath.Join(baseImp, t.ServerPackage, t.APIPackage, k))
 	}
 
 	defaultConsumes := "application/json"
@@ -382,7 +407,7 @@ func (t *testGenerator) makeCodegenTest() genTest {
 		IncludeTCK:          t.IncludeTCK,
 		Principal:           t.Principal,
 		SwaggerJSON:         fmt.Sprintf("%#v", jsonb),
-	}
+	}, nil
 }
 
 type genTest struct {
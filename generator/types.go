@@ -1,6 +1,8 @@
 package generator
 
 import (
+	"encoding/json"
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -8,11 +10,11 @@ import (
 	"github.com/go-swagger/go-swagger/swag"
 )
 
-func typeForSchemaOrArray(schemas *spec.SchemaOrArray, modelsPkg string) string {
+func (t *typeResolver) typeForSchemaOrArray(schemas *spec.SchemaOrArray, modelsPkg string) string {
 	if schemas == nil || len(schemas.Schemas) > 0 {
 		return "interface{}"
 	}
-	return typeForSchema(schemas.Schema, modelsPkg)
+	return t.typeForSchema(schemas.Schema, modelsPkg)
 }
 
 var goImports = map[string]string{
@@ -121,17 +123,17 @@ func init() {
 	}
 }
 
-func typeForParameter(param spec.Parameter) string {
-	return resolveSimpleType(param.Type, param.Format, param.Items)
+func (t *typeResolver) typeForParameter(param spec.Parameter) string {
+	return t.resolveSimpleType(param.Type, param.Format, param.Items)
 }
 
-func resolveSimpleType(tn, fmt string, items *spec.Items) string {
+func (t *typeResolver) resolveSimpleType(tn, fmt string, items *spec.Items) string {
 	if fmt != "" {
-		if tpe, ok := typeMapping[strings.Replace(fmt, "-", "", -1)]; ok {
+		if tpe, ok := t.typeMapping[strings.Replace(fmt, "-", "", -1)]; ok {
 			return tpe
 		}
 	}
-	if tpe, ok := typeMapping[tn]; ok {
+	if tpe, ok := t.typeMapping[tn]; ok {
 		return tpe
 	}
 
@@ -139,12 +141,12 @@ func resolveSimpleType(tn, fmt string, items *spec.Items) string {
 		if items == nil {
 			return "[]interface{}"
 		}
-		return "[]" + resolveSimpleType(items.Type, items.Format, items.Items)
+		return "[]" + t.resolveSimpleType(items.Type, items.Format, items.Items)
 	}
 	return tn
 }
 
-func typeForSchema(schema *spec.Schema, modelsPkg string) string {
+func (t *typeResolver) typeForSchema(schema *spec.Schema, modelsPkg string) string {
 	if schema == nil {
 		return "interface{}"
 	}
@@ -156,30 +158,30 @@ func typeForSchema(schema *spec.Schema, modelsPkg string) string {
 		return tn
 	}
 	if schema.Format != "" {
-		if tpe, ok := typeMapping[strings.Replace(schema.Format, "-", "", -1)]; ok {
+		if tpe, ok := t.typeMapping[strings.Replace(schema.Format, "-", "", -1)]; ok {
 			return tpe
 		}
 	}
 	if schema.Type.Contains("array") {
-		return "[]" + typeForSchemaOrArray(schema.Items, modelsPkg)
+		return "[]" + t.typeForSchemaOrArray(schema.Items, modelsPkg)
 	}
 	if schema.Type.Contains("file") {
-		return typeMapping["file"]
+		return t.typeMapping["file"]
 	}
 	if schema.Type.Contains("number") {
-		return typeMapping["number"]
+		return t.typeMapping["number"]
 	}
 	if schema.Type.Contains("integer") {
-		return typeMapping["integer"]
+		return t.typeMapping["integer"]
 	}
 	if schema.Type.Contains("boolean") {
-		return typeMapping["boolean"]
+		return t.typeMapping["boolean"]
 	}
 	if schema.Type.Contains("string") {
 		return "string"
 	}
 	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
-		return "map[string]" + typeForSchema(schema.AdditionalProperties.Schema, modelsPkg)
+		return "map[string]" + t.typeForSchema(schema.AdditionalProperties.Schema, modelsPkg)
 	}
 	if schema.Type.Contains("object") || schema.Type.Contains("") || len(schema.Type) == 0 {
 		return "map[string]interface{}"
@@ -231,3 +233,131 @@ var customFormatters = map[string]struct{}{
 	"strfmt.Base64":     struct{}{},
 	// "strfmt.Duration":   struct{}{},
 }
+
+// FormatMapping describes how a string format, registered with a strfmt.Registry at runtime,
+// is represented in the generated code.
+// The go type needs to be convertible to a string so the value can be validated by the registry.
+type FormatMapping struct {
+	// Format the name of the format as it appears in the spec, for example money
+	Format string `json:"format"`
+	// GoType the qualified go type to use, for example money.Amount
+	GoType string `json:"goType"`
+	// Import the import path of the package that contains the go type
+	Import string `json:"import,omitempty"`
+	// Zero the zero value for the go type, defaults to nil
+	Zero string `json:"zero,omitempty"`
+	// Converter the function that converts a string into the go type, used to bind parameters
+	Converter string `json:"converter,omitempty"`
+}
+
+// LoadFormatMappings reads a list of format mappings from a yaml or json file
+func LoadFormatMappings(path string) ([]FormatMapping, error) {
+	data, err := swag.YAMLDoc(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []FormatMapping
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// typeResolver resolves the go types of a generation run, it knows the builtin formats and the format mappings of the run.
+// The mappings of one run don't leak into another, so runs with different mappings can happen at the same time.
+type typeResolver struct {
+	typeMapping      map[string]string
+	customFormatters map[string]struct{}
+	goImports        map[string]string
+	zeroes           map[string]string
+	stringConverters map[string]string
+}
+
+// newTypeResolver creates a type resolver for the builtin formats and the format mappings
+func newTypeResolver(mappings []FormatMapping) (*typeResolver, error) {
+	t := &typeResolver{
+		typeMapping:      make(map[string]string, len(typeMapping)+len(mappings)),
+		customFormatters: make(map[string]struct{}, len(customFormatters)+len(mappings)),
+		goImports:        make(map[string]string, len(goImports)+len(mappings)),
+		zeroes:           make(map[string]string, len(zeroes)+len(mappings)),
+		stringConverters: make(map[string]string, len(stringConverters)+len(mappings)),
+	}
+	for k, v := range typeMapping {
+		t.typeMapping[k] = v
+	}
+	for k, v := range customFormatters {
+		t.customFormatters[k] = v
+	}
+	for k, v := range goImports {
+		t.goImports[k] = v
+	}
+	for k, v := range zeroes {
+		t.zeroes[k] = v
+	}
+	for k, v := range stringConverters {
+		t.stringConverters[k] = v
+	}
+
+	for _, mapping := range mappings {
+		if err := t.addFormatMapping(mapping); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// addFormatMapping makes the resolver use the go type of the mapping for its format
+func (t *typeResolver) addFormatMapping(mapping FormatMapping) error {
+	format := strings.Replace(mapping.Format, "-", "", -1)
+	if format == "" {
+		return fmt.Errorf("format mapping for %q requires a format", mapping.GoType)
+	}
+	if mapping.GoType == "" {
+		return fmt.Errorf("format mapping for %q requires a go type", mapping.Format)
+	}
+	if mapping.Import != "" && !strings.Contains(mapping.GoType, ".") {
+		return fmt.Errorf("format mapping for %q has an import but go type %q is not qualified", mapping.Format, mapping.GoType)
+	}
+
+	t.typeMapping[format] = mapping.GoType
+	t.customFormatters[mapping.GoType] = struct{}{}
+	if mapping.Import != "" {
+		t.goImports[mapping.GoType] = mapping.Import
+	}
+	if mapping.Zero != "" {
+		t.zeroes[mapping.GoType] = mapping.Zero
+	}
+	if mapping.Converter != "" {
+		t.stringConverters[mapping.GoType] = mapping.Converter
+	}
+	return nil
+}
+
+func (t *typeResolver) isCustomFormatter(tpe string) bool {
+	_, ok := t.customFormatters[tpe]
+	return ok
+}
+
+// typeImports returns the imports required for the go types, keyed by package name
+func (t *typeResolver) typeImports(types ...string) map[string]string {
+	var result map[string]string
+	for _, tpe := range types {
+		for {
+			trimmed := strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(tpe, "[]"), "*"), "map[string]")
+			if trimmed == tpe {
+				break
+			}
+			tpe = trimmed
+		}
+		importPath, ok := t.goImports[tpe]
+		if !ok {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[tpe[:strings.Index(tpe, ".")]] = importPath
+	}
+	return result
+}
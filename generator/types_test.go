@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+var moneyMapping = FormatMapping{
+	Format:    "money",
+	GoType:    "money.Amount",
+	Import:    "example.com/money",
+	Zero:      "money.Amount{}",
+	Converter: "money.Parse",
+}
+
+func TestTypeResolverFormatMapping(t *testing.T) {
+	types, err := newTypeResolver([]FormatMapping{moneyMapping})
+	if !assert.NoError(t, err) {
+		return
+	}
+	schema := spec.StringProperty()
+	schema.Format = "money"
+	assert.Equal(t, "money.Amount", types.typeForSchema(schema, ""))
+	assert.Equal(t, "[]money.Amount", types.resolveSimpleType("array", "", spec.NewItems().Typed("string", "money")))
+	assert.True(t, types.isCustomFormatter("money.Amount"))
+	assert.Equal(t, map[string]string{"money": "example.com/money"}, types.typeImports("string", "[]money.Amount"))
+	assert.Equal(t, "money.Amount{}", types.zeroes["money.Amount"])
+	assert.Equal(t, "money.Parse", types.stringConverters["money.Amount"])
+
+	// the builtin mappings stay untouched
+	builtin, err := newTypeResolver(nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "string", builtin.typeForSchema(schema, ""))
+		assert.False(t, builtin.isCustomFormatter("money.Amount"))
+		assert.Empty(t, builtin.typeImports("money.Amount"))
+	}
+	_, ok := typeMapping["money"]
+	assert.False(t, ok)
+}
+
+func TestTypeResolverInvalidMapping(t *testing.T) {
+	for _, mapping := range []FormatMapping{
+		{GoType: "money.Amount"},
+		{Format: "money"},
+		{Format: "money", GoType: "Amount", Import: "example.com/money"},
+	} {
+		_, err := newTypeResolver([]FormatMapping{mapping})
+		assert.Error(t, err, "mapping: %+v", mapping)
+	}
+}
+
+func TestRenderModelFormatMappingsPerRun(t *testing.T) {
+	mappings := map[string][]FormatMapping{
+		"money.Amount": {moneyMapping},
+		"cash.Value":   {{Format: "money", GoType: "cash.Value", Import: "example.com/cash"}},
+		"string":       nil,
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	rendered := make(map[string]string)
+	for goType, mapping := range mappings {
+		wg.Add(1)
+		go func(goType string, mapping []FormatMapping) {
+			defer wg.Done()
+			opts := testGenOpts("../fixtures/codegen/custom-formats.yml", "invoices")
+			opts.FormatMappings = mapping
+			files, _, err := RenderModel([]string{"invoice"}, true, false, opts)
+			if assert.NoError(t, err) && assert.Len(t, files, 1) {
+				mu.Lock()
+				rendered[goType] = string(files[0].Content)
+				mu.Unlock()
+			}
+		}(goType, mapping)
+	}
+	wg.Wait()
+
+	assert.Contains(t, rendered["money.Amount"], "Total money.Amount")
+	assert.Contains(t, rendered["money.Amount"], `"example.com/money"`)
+	assert.Contains(t, rendered["cash.Value"], "Total cash.Value")
+	assert.Contains(t, rendered["cash.Value"], `"example.com/cash"`)
+	assert.NotContains(t, rendered["cash.Value"], "money")
+	assert.Contains(t, rendered["string"], "Total string")
+}
@@ -5,7 +5,11 @@ package client
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
 
 	"github.com/go-swagger/go-swagger/httpkit"
 	"github.com/go-swagger/go-swagger/spec"
@@ -20,13 +24,18 @@ type Runtime struct {
 	Producers        map[string]httpkit.Producer
 	Transport        http.Transport
 	Spec             *spec.Document
+	Scheme           string
 	Host             string
 	BasePath         string
 	client           *http.Client
 	Formats          strfmt.Registry
+
+	// Authenticate adds the credentials to every request before it is sent, when set
+	Authenticate func(*http.Request) error
 }
 
 // New creates a new default runtim for a swagger api client.
+// The scheme, host and base path are taken from the spec when it is provided.
 func New(swaggerSpec *spec.Document) *Runtime {
 	var rt Runtime
 	rt.DefaultMediaType = "application/json"
@@ -36,10 +45,21 @@ func New(swaggerSpec *spec.Document) *Runtime {
 	rt.Producers = map[string]httpkit.Producer{
 		"application/json": httpkit.JSONProducer(),
 	}
+	rt.Scheme = "http"
+	if swaggerSpec != nil {
+		rt.Spec = swaggerSpec
+		rt.Host = swaggerSpec.Host()
+		rt.BasePath = swaggerSpec.BasePath()
+		if schemes := swaggerSpec.Spec().Schemes; len(schemes) > 0 {
+			rt.Scheme = schemes[0]
+		}
+	}
+	rt.client = &http.Client{Transport: &rt.Transport}
 	return &rt
 }
 
-// Request represents a swagger client request
+// Request represents a swagger client request.
+// The path is the path of the operation in the spec, the parameters are a map with a value per parameter name.
 type Request struct {
 	Path      string
 	Method    string
@@ -65,6 +85,45 @@ func validateRequest(request *Request) error {
 	return nil
 }
 
+// parametersFor returns the parameters of the path and operation of a request
+func (r *Runtime) parametersFor(request *Request) []spec.Parameter {
+	if r.Spec == nil {
+		return request.Operation.Parameters
+	}
+	if _, ok := r.Spec.OperationFor(request.Method, request.Path); !ok {
+		return request.Operation.Parameters
+	}
+	var result []spec.Parameter
+	for _, param := range r.Spec.ParamsFor(request.Method, request.Path) {
+		result = append(result, param)
+	}
+	return result
+}
+
+// paramValues returns the string values of a parameter, slices are joined according to the collection format
+// unless they are sent as multiple values
+func paramValues(param spec.Parameter, value interface{}) []string {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return []string{fmt.Sprint(value)}
+	}
+	var values []string
+	for i := 0; i < rv.Len(); i++ {
+		values = append(values, fmt.Sprint(rv.Index(i).Interface()))
+	}
+	switch param.CollectionFormat {
+	case "multi":
+		return values
+	case "ssv":
+		return []string{strings.Join(values, " ")}
+	case "tsv":
+		return []string{strings.Join(values, "\t")}
+	case "pipes":
+		return []string{strings.Join(values, "|")}
+	}
+	return []string{strings.Join(values, ",")}
+}
+
 // Submit a request and when there is a body on success it will turn that into the result
 // all other things are turned into an api error for swagger which retains the status code
 func (r *Runtime) Submit(request *Request, result interface{}) error {
@@ -72,41 +131,93 @@ func (r *Runtime) Submit(request *Request, result interface{}) error {
 		return err
 	}
 
-	p := request.Params.(map[string]interface{})
+	p, _ := request.Params.(map[string]interface{})
 
 	// TODO: Work out if there is a file involved and handle that
 	// if a file is involved only form/multipart requests will be made
 	consumerMediaType := r.DefaultMediaType
 	producerMediaType := r.DefaultMediaType
 
+	path := request.Path
+	query := make(url.Values)
+	form := make(url.Values)
+	headers := make(http.Header)
 	var body *bytes.Buffer
-	if pet, ok := p["pet"]; ok {
-		body = bytes.NewBuffer(nil)
-		prod := r.Producers[producerMediaType]
-		if err := prod.Produce(body, pet); err != nil {
-			return err
+	for _, param := range r.parametersFor(request) {
+		value, ok := p[param.Name]
+		if !ok || value == nil {
+			continue
+		}
+		switch param.In {
+		case "path":
+			path = strings.Replace(path, "{"+param.Name+"}", paramValues(param, value)[0], -1)
+		case "query":
+			for _, v := range paramValues(param, value) {
+				query.Add(param.Name, v)
+			}
+		case "header":
+			headers.Set(param.Name, paramValues(param, value)[0])
+		case "formData":
+			for _, v := range paramValues(param, value) {
+				form.Add(param.Name, v)
+			}
+		case "body":
+			body = bytes.NewBuffer(nil)
+			prod, ok := r.Producers[producerMediaType]
+			if !ok {
+				return fmt.Errorf("%s: no producer for %q", request.Operation.ID, producerMediaType)
+			}
+			if err := prod.Produce(body, value); err != nil {
+				return err
+			}
 		}
 	}
+	if body == nil && len(form) > 0 {
+		producerMediaType = "application/x-www-form-urlencoded"
+		body = bytes.NewBufferString(form.Encode())
+	}
 
-	req, _ := http.NewRequest(request.Method, request.Path, body)
-	req.Header.Add(httpkit.HeaderContentType, producerMediaType) // use selected producer mime type
-	req.Header.Add(httpkit.HeaderAccept, consumerMediaType)      // use selected consumer mime type
-	if body != nil && body.Len() > 0 {
+	u := url.URL{
+		Scheme:   r.Scheme,
+		Host:     r.Host,
+		Path:     strings.TrimSuffix(r.BasePath, "/") + path,
+		RawQuery: query.Encode(),
+	}
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = body
+	}
+	req, err := http.NewRequest(request.Method, u.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	req.Header.Add(httpkit.HeaderAccept, consumerMediaType) // use selected consumer mime type
+	if body != nil {
+		req.Header.Add(httpkit.HeaderContentType, producerMediaType) // use selected producer mime type
 		req.Header.Add("Content-Length", fmt.Sprintf("%d", body.Len()))
 	}
+	if r.Authenticate != nil {
+		if err := r.Authenticate(req); err != nil {
+			return err
+		}
+	}
 
 	res, err := r.client.Do(req) // make requests, by default follows 10 redirects before failing
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 
 	sc := res.StatusCode / 100 // read the response
 	switch sc {
 	case 2:
-		if res.StatusCode == 200 { // only 200 should parse the response body in the result
+		if res.StatusCode != http.StatusNoContent && result != nil { // an empty body leaves the result untouched
 			cons, ok := r.Consumers[consumerMediaType]
 			if ok {
-				if err := cons.Consume(res.Body, result); err != nil {
+				if err := cons.Consume(res.Body, result); err != nil && err != io.EOF {
 					return err
 				}
 			} else {
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+var petstoreJSON = json.RawMessage([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "petstore", "version": "1.0.0"},
+  "basePath": "/api",
+  "paths": {
+    "/pets/{id}": {
+      "parameters": [{"name": "id", "in": "path", "type": "integer", "required": true}],
+      "put": {
+        "operationId": "updatePet",
+        "parameters": [
+          {"name": "tags", "in": "query", "type": "array", "items": {"type": "string"}},
+          {"name": "X-Request-Id", "in": "header", "type": "string"},
+          {"name": "pet", "in": "body", "schema": {"type": "object"}}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "delete": {
+        "operationId": "deletePet",
+        "responses": {"204": {"description": "deleted"}}
+      }
+    }
+  }
+}`))
+
+func newTestRuntime(t *testing.T, handler http.HandlerFunc) (*Runtime, *httptest.Server) {
+	doc, err := spec.New(petstoreJSON, "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	server := httptest.NewServer(handler)
+	rt := New(doc)
+	u, _ := url.Parse(server.URL)
+	rt.Host = u.Host
+	return rt, server
+}
+
+func TestRuntimeDefaultsFromSpec(t *testing.T) {
+	doc, err := spec.New(petstoreJSON, "")
+	if assert.NoError(t, err) {
+		rt := New(doc)
+		assert.Equal(t, "http", rt.Scheme)
+		assert.Equal(t, "/api", rt.BasePath)
+		assert.Equal(t, doc, rt.Spec)
+	}
+}
+
+func TestRuntimeSubmitParams(t *testing.T) {
+	var received *http.Request
+	var body map[string]interface{}
+	rt, server := newTestRuntime(t, func(rw http.ResponseWriter, r *http.Request) {
+		received = r
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write(b)
+	})
+	defer server.Close()
+
+	op, _ := rt.Spec.OperationForName("updatePet")
+	var result interface{}
+	err := rt.Submit(&Request{
+		Method:    "PUT",
+		Path:      "/pets/{id}",
+		Operation: op,
+		Params: map[string]interface{}{
+			"id":           int64(3),
+			"tags":         []string{"a", "b"},
+			"X-Request-Id": "abc",
+			"pet":          map[string]interface{}{"name": "fido"},
+		},
+	}, &result)
+
+	if assert.NoError(t, err) && assert.NotNil(t, received) {
+		assert.Equal(t, "PUT", received.Method)
+		assert.Equal(t, "/api/pets/3", received.URL.Path)
+		assert.Equal(t, "a,b", received.URL.Query().Get("tags"))
+		assert.Equal(t, "abc", received.Header.Get("X-Request-Id"))
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, map[string]interface{}{"name": "fido"}, body)
+		assert.Equal(t, map[string]interface{}{"name": "fido"}, result)
+	}
+}
+
+func TestRuntimeSubmitAuthenticate(t *testing.T) {
+	var user, pass string
+	rt, server := newTestRuntime(t, func(rw http.ResponseWriter, r *http.Request) {
+		user, pass, _ = r.BasicAuth()
+		rw.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	rt.Authenticate = func(r *http.Request) error {
+		r.SetBasicAuth("admin", "secret")
+		return nil
+	}
+	op, _ := rt.Spec.OperationForName("deletePet")
+	var result interface{}
+	err := rt.Submit(&Request{Method: "DELETE", Path: "/pets/{id}", Operation: op, Params: map[string]interface{}{"id": 1}}, &result)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "admin", user)
+		assert.Equal(t, "secret", pass)
+		assert.Nil(t, result)
+	}
+}
+
+func TestRuntimeSubmitError(t *testing.T) {
+	rt, server := newTestRuntime(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(`{"message":"not found"}`))
+	})
+	defer server.Close()
+
+	op, _ := rt.Spec.OperationForName("deletePet")
+	err := rt.Submit(&Request{Method: "DELETE", Path: "/pets/{id}", Operation: op, Params: map[string]interface{}{"id": 1}}, nil)
+	if assert.Error(t, err) {
+		apiErr, ok := err.(*APIError)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusNotFound, apiErr.Code)
+			assert.Equal(t, "deletePet", apiErr.OperationName)
+			assert.Equal(t, map[string]interface{}{"message": "not found"}, apiErr.Value)
+		}
+	}
+}
@@ -5,6 +5,8 @@ const (
 	HeaderContentType = "Content-Type"
 	// HeaderAccept the Accept header
 	HeaderAccept = "Accept"
+	// HeaderVary the Vary header, it lists the request headers a response depends on
+	HeaderVary = "Vary"
 
 	charsetKey = "charset"
 
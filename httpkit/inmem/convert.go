@@ -0,0 +1,66 @@
+package inmem
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Convert copies a value into a target of another model by the json names of their fields.
+// The fields of the value that the target has no place for would get lost, so instead of
+// dropping them Convert fails with their names. Fields with a zero value are ignored.
+func Convert(value, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return err
+	}
+
+	var source map[string]interface{}
+	if err := json.Unmarshal(data, &source); err != nil {
+		return err
+	}
+	converted, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	var kept map[string]interface{}
+	if err := json.Unmarshal(converted, &kept); err != nil {
+		return err
+	}
+
+	var lost []string
+	for k, v := range source {
+		if _, ok := kept[k]; !ok && !isZero(v) {
+			lost = append(lost, k)
+		}
+	}
+	if len(lost) > 0 {
+		sort.Strings(lost)
+		return fmt.Errorf("%s has no field for %s", reflect.Indirect(reflect.ValueOf(target)).Type(), strings.Join(lost, ", "))
+	}
+	return nil
+}
+
+// isZero tells whether a json value is the zero value of its type, omitempty leaves those out
+func isZero(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
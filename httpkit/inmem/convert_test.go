@@ -0,0 +1,39 @@
+package inmem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type newPet struct {
+	Name  string   `json:"name"`
+	Tag   string   `json:"tag,omitempty"`
+	Owner string   `json:"owner,omitempty"`
+	Toys  []string `json:"toys,omitempty"`
+}
+
+type pet struct {
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+func TestConvert(t *testing.T) {
+	var value pet
+	if assert.NoError(t, Convert(&newPet{Name: "fido", Tag: "dog"}, &value)) {
+		assert.Equal(t, pet{Name: "fido", Tag: "dog"}, value)
+	}
+
+	// the fields that are empty don't get lost
+	value = pet{}
+	if assert.NoError(t, Convert(newPet{Name: "fido"}, &value)) {
+		assert.Equal(t, pet{Name: "fido"}, value)
+	}
+}
+
+func TestConvertLostFields(t *testing.T) {
+	var value pet
+	err := Convert(&newPet{Name: "fido", Owner: "alice", Toys: []string{"ball"}}, &value)
+	assert.EqualError(t, err, "inmem.pet has no field for owner, toys")
+}
@@ -0,0 +1,121 @@
+// Package inmem provides the storage for the in-memory fakes the generator creates for an API.
+package inmem
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Key builds a store key from the values of path parameters
+func Key(parts ...interface{}) string {
+	strs := make([]string, 0, len(parts))
+	for _, p := range parts {
+		strs = append(strs, fmt.Sprint(p))
+	}
+	return strings.Join(strs, "/")
+}
+
+// Store is a thread safe in-memory store for the values of a single definition.
+// Values are keyed by the path parameters that identify them and are listed in the order they were created.
+type Store struct {
+	lock  sync.RWMutex
+	items map[string]interface{}
+	keys  []string
+	seq   int64
+}
+
+// NewStore creates a new empty store
+func NewStore() *Store {
+	return &Store{items: make(map[string]interface{})}
+}
+
+// NextID returns the next value of the sequence of this store, the first value is 1
+func (s *Store) NextID() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.seq++
+	return s.seq
+}
+
+// Get returns the value for the specified key
+func (s *Store) Get(key string) (interface{}, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	v, ok := s.items[key]
+	return v, ok
+}
+
+// Create adds the value when there is nothing stored at the key yet, it returns false otherwise
+func (s *Store) Create(key string, value interface{}) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.items[key]; ok {
+		return false
+	}
+	s.items[key] = value
+	s.keys = append(s.keys, key)
+	return true
+}
+
+// Put stores the value at the key, replacing any value that was there already
+func (s *Store) Put(key string, value interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.items[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+	s.items[key] = value
+}
+
+// Delete removes the value at the key, it returns false when there was nothing to remove
+func (s *Store) Delete(key string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.items[key]; !ok {
+		return false
+	}
+	delete(s.items, key)
+	for i, k := range s.keys {
+		if k == key {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// List returns the values that are nested under the parent key, in the order they were created.
+// An empty parent lists all the values in the store.
+func (s *Store) List(parent string) []interface{} {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	prefix := parent
+	if prefix != "" {
+		prefix += "/"
+	}
+	var result []interface{}
+	for _, k := range s.keys {
+		if strings.HasPrefix(k, prefix) {
+			result = append(result, s.items[k])
+		}
+	}
+	return result
+}
+
+// Len returns the number of values in the store
+func (s *Store) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return len(s.keys)
+}
+
+// Reset removes all the values from the store and restarts the sequence
+func (s *Store) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.items = make(map[string]interface{})
+	s.keys = nil
+	s.seq = 0
+}
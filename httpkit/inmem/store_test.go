@@ -0,0 +1,60 @@
+package inmem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "", Key())
+	assert.Equal(t, "1", Key(int64(1)))
+	assert.Equal(t, "owner/3", Key("owner", 3))
+}
+
+func TestStoreCRUD(t *testing.T) {
+	s := NewStore()
+
+	assert.True(t, s.Create("1", "fido"))
+	assert.False(t, s.Create("1", "rex"))
+
+	v, ok := s.Get("1")
+	assert.True(t, ok)
+	assert.Equal(t, "fido", v)
+
+	s.Put("1", "rex")
+	s.Put("2", "lassie")
+	v, _ = s.Get("1")
+	assert.Equal(t, "rex", v)
+	assert.Equal(t, 2, s.Len())
+
+	assert.True(t, s.Delete("1"))
+	assert.False(t, s.Delete("1"))
+	_, ok = s.Get("1")
+	assert.False(t, ok)
+	assert.Equal(t, []interface{}{"lassie"}, s.List(""))
+}
+
+func TestStoreList(t *testing.T) {
+	s := NewStore()
+	s.Put(Key("a", 2), "a2")
+	s.Put(Key("b", 1), "b1")
+	s.Put(Key("a", 1), "a1")
+	s.Put(Key("ab", 1), "ab1")
+
+	assert.Equal(t, []interface{}{"a2", "a1"}, s.List("a"))
+	assert.Equal(t, []interface{}{"b1"}, s.List("b"))
+	assert.Len(t, s.List(""), 4)
+	assert.Empty(t, s.List("c"))
+}
+
+func TestStoreSequenceAndReset(t *testing.T) {
+	s := NewStore()
+	assert.Equal(t, int64(1), s.NextID())
+	assert.Equal(t, int64(2), s.NextID())
+	s.Put("1", "fido")
+
+	s.Reset()
+	assert.Equal(t, 0, s.Len())
+	assert.Equal(t, int64(1), s.NextID())
+}
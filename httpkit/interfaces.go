@@ -21,7 +21,8 @@ func (s OperationHandlerFunc) Handle(data interface{}) (interface{}, error) {
 	return s(data)
 }
 
-// OperationHandler a handler for a swagger operation
+// OperationHandler a handler for a swagger operation,
+// it returns a *Response when the status code or the headers of the response aren't the default ones
 type OperationHandler interface {
 	Handle(interface{}) (interface{}, error)
 }
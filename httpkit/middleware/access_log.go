@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-swagger/go-swagger/spec"
+)
+
+const (
+	// XSensitive is the vendor extension that marks a parameter whose value is redacted in the access log with x-sensitive: true
+	XSensitive = "x-sensitive"
+	// HeaderRequestID is the header the request ID in the access log is taken from
+	HeaderRequestID = "X-Request-Id"
+	// Redacted replaces the values of sensitive parameters and credentials in the access log
+	Redacted = "[REDACTED]"
+)
+
+// AccessRecord describes a request that was served by the API
+type AccessRecord struct {
+	Time         time.Time         `json:"time"`
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	PathTemplate string            `json:"pathTemplate,omitempty"`
+	OperationID  string            `json:"operationId,omitempty"`
+	Params       map[string]string `json:"params,omitempty"`
+	Status       int               `json:"status"`
+	LatencyMs    float64           `json:"latencyMs"`
+	RequestSize  int64             `json:"requestSize"`
+	ResponseSize int64             `json:"responseSize"`
+	Principal    string            `json:"principal,omitempty"`
+	RequestID    string            `json:"requestId,omitempty"`
+}
+
+// AccessLogger writes the access log of an API, it gets one record for every request
+type AccessLogger interface {
+	LogAccess(*AccessRecord)
+}
+
+// AccessLoggerFunc turns a function into an access logger
+type AccessLoggerFunc func(*AccessRecord)
+
+// LogAccess writes the record by calling the function
+func (fn AccessLoggerFunc) LogAccess(record *AccessRecord) {
+	fn(record)
+}
+
+// JSONAccessLogger creates an access logger that writes every record as a line of JSON
+func JSONAccessLogger(w io.Writer) AccessLogger {
+	var lock sync.Mutex
+	enc := json.NewEncoder(w)
+	return AccessLoggerFunc(func(record *AccessRecord) {
+		lock.Lock()
+		defer lock.Unlock()
+		enc.Encode(record)
+	})
+}
+
+// newAccessLog creates a middleware that writes a record to the access logger for every request
+func newAccessLog(ctx *Context, next http.Handler) http.Handler {
+	if ctx.accessLogger == nil {
+		return next
+	}
+	logger := ctx.accessLogger
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// the router changes the path of the request, so the values are collected up front
+		r = WithRequestValues(r)
+		record := &AccessRecord{
+			Time:      time.Now().UTC(),
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			RequestID: r.Header.Get(HeaderRequestID),
+		}
+		query := r.URL.Query()
+		body := &countingBody{ReadCloser: r.Body}
+		if r.Body != nil {
+			r.Body = body
+		}
+		recorder := &statusRecorder{rw: rw}
+
+		defer func() {
+			if recorder.code == 0 {
+				recorder.code = http.StatusOK
+			}
+			record.Status = recorder.code
+			record.LatencyMs = float64(time.Since(record.Time)) / float64(time.Millisecond)
+			record.RequestSize = body.size
+			record.ResponseSize = int64(recorder.size)
+			if principal, ok := SecurityPrincipalFrom(r.Context()); ok {
+				record.Principal = principalName(principal)
+			}
+			if route, ok := MatchedRouteFrom(r.Context()); ok {
+				ctx.describeRoute(record, route, r, query)
+			} else if len(query) > 0 {
+				record.Path += "?" + redactQuery(query, ctx.querySecrets())
+			}
+			logger.LogAccess(record)
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// describeRoute adds the operation of a route and the values of its parameters to a record,
+// the sensitive parameters and the credentials of the security schemes are redacted
+func (c *Context) describeRoute(record *AccessRecord, route *MatchedRoute, r *http.Request, query url.Values) {
+	record.PathTemplate = route.PathPattern
+	if route.Operation != nil {
+		record.OperationID = route.Operation.ID
+	}
+
+	// a request can carry the credentials of a scheme its operation doesn't use, those are redacted as well
+	sensitive := c.querySecrets()
+	if route.Operation != nil {
+		for _, scheme := range c.spec.SecurityDefinitionsFor(route.Operation) {
+			if scheme.Type == "apiKey" {
+				sensitive[scheme.In+":"+strings.ToLower(scheme.Name)] = true
+			}
+		}
+	}
+	isSensitive := func(param spec.Parameter) bool {
+		if enabled, ok := param.Extensions.GetBool(XSensitive); ok && enabled {
+			return true
+		}
+		return sensitive[param.In+":"+strings.ToLower(param.Name)]
+	}
+
+	pathValues := make(map[string]string)
+	for _, param := range route.Parameters {
+		var value string
+		var ok bool
+		switch param.In {
+		case "path":
+			value, ok = route.Params.Get(param.Name), true
+		case "query":
+			if _, ok = query[param.Name]; ok {
+				value = query.Get(param.Name)
+			}
+			if isSensitive(param) {
+				sensitive["query:"+strings.ToLower(param.Name)] = true
+			}
+		case "header":
+			if _, ok = r.Header[http.CanonicalHeaderKey(param.Name)]; ok {
+				value = r.Header.Get(param.Name)
+			}
+		}
+		if !ok {
+			continue
+		}
+		if isSensitive(param) {
+			value = Redacted
+		}
+		if param.In == "path" {
+			pathValues[param.Name] = value
+		}
+		if record.Params == nil {
+			record.Params = make(map[string]string)
+		}
+		record.Params[param.Name] = value
+	}
+
+	// the path is rebuilt from the template when a path parameter is redacted
+	path := route.PathPattern
+	redactedPath := false
+	for name, value := range pathValues {
+		if value == Redacted {
+			redactedPath = true
+		}
+		path = strings.Replace(path, "{"+name+"}", value, -1)
+	}
+	if redactedPath {
+		record.Path = strings.TrimSuffix(route.BasePath, "/") + path
+	}
+
+	if len(query) > 0 {
+		record.Path += "?" + redactQuery(query, sensitive)
+	}
+}
+
+// querySecrets returns the query parameters that carry the credentials of the api key security schemes of the spec
+func (c *Context) querySecrets() map[string]bool {
+	sensitive := make(map[string]bool)
+	for _, scheme := range c.spec.Spec().SecurityDefinitions {
+		if scheme.Type == "apiKey" && scheme.In == "query" {
+			sensitive["query:"+strings.ToLower(scheme.Name)] = true
+		}
+	}
+	return sensitive
+}
+
+// redactQuery encodes a query string with the values of the sensitive parameters redacted
+func redactQuery(query url.Values, sensitive map[string]bool) string {
+	redacted := make(url.Values, len(query))
+	for k, v := range query {
+		if sensitive["query:"+strings.ToLower(k)] {
+			redacted[k] = []string{Redacted}
+			continue
+		}
+		redacted[k] = v
+	}
+	return redacted.Encode()
+}
+
+// principalName returns the identity of a principal for the access log, only a string or a fmt.Stringer has one
+func principalName(principal interface{}) string {
+	switch p := principal.(type) {
+	case string:
+		return p
+	case fmt.Stringer:
+		return p.String()
+	}
+	return ""
+}
+
+// countingBody counts the bytes that are read from a request body
+type countingBody struct {
+	io.ReadCloser
+	size int64
+}
+
+func (c *countingBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.size += int64(n)
+	return n, err
+}
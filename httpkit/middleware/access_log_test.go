@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func accessLogHandler(t *testing.T) (http.Handler, *[]*AccessRecord) {
+	doc, api := petstore.NewAPI(t)
+	params := doc.Spec().Paths.Paths["/pets"].Get.Parameters
+	for i := range params {
+		if params[i].Name == "status" {
+			params[i].Extensions = spec.Extensions{}
+			params[i].Extensions.Add(XSensitive, true)
+		}
+	}
+
+	var records []*AccessRecord
+	context := NewContext(doc, api, nil)
+	context.SetAccessLogger(AccessLoggerFunc(func(record *AccessRecord) {
+		records = append(records, record)
+	}))
+	return context.APIHandler(), &records
+}
+
+func TestAccessLogRecord(t *testing.T) {
+	handler, records := accessLogHandler(t)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets?limit=10&status=secret", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	request.Header.Set(HeaderRequestID, "abc-123")
+	request.SetBasicAuth("admin", "admin")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+
+	if assert.Len(t, *records, 1) {
+		record := (*records)[0]
+		assert.Equal(t, "GET", record.Method)
+		assert.Equal(t, "/api/pets?limit=10&status=%5BREDACTED%5D", record.Path)
+		assert.Equal(t, "/pets", record.PathTemplate)
+		assert.Equal(t, "getAllPets", record.OperationID)
+		assert.Equal(t, map[string]string{"limit": "10", "status": Redacted}, record.Params)
+		assert.Equal(t, 200, record.Status)
+		assert.EqualValues(t, recorder.Body.Len(), record.ResponseSize)
+		assert.Equal(t, "admin", record.Principal)
+		assert.Equal(t, "abc-123", record.RequestID)
+		assert.False(t, record.Time.IsZero())
+		assert.True(t, record.LatencyMs >= 0)
+	}
+}
+
+func TestAccessLogUnmatched(t *testing.T) {
+	handler, records := accessLogHandler(t)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/unknown?q=1", nil)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 404, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 401, recorder.Code)
+
+	if assert.Len(t, *records, 2) {
+		assert.Equal(t, "/api/unknown?q=1", (*records)[0].Path)
+		assert.Equal(t, 404, (*records)[0].Status)
+		assert.Empty(t, (*records)[0].OperationID)
+
+		assert.Equal(t, "getAllPets", (*records)[1].OperationID)
+		assert.Equal(t, 401, (*records)[1].Status)
+		assert.Empty(t, (*records)[1].Principal)
+	}
+}
+
+func TestAccessLogUnmatchedRedactsCredentials(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	doc.Spec().SecurityDefinitions["queryKey"] = spec.APIKeyAuth("api_key", "query")
+
+	var records []*AccessRecord
+	context := NewContext(doc, api, nil)
+	context.SetAccessLogger(AccessLoggerFunc(func(record *AccessRecord) {
+		records = append(records, record)
+	}))
+	handler := context.APIHandler()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/unknown?API_KEY=token123&q=1", nil)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 404, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("PATCH", "/api/pets?api_key=token123", nil)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 405, recorder.Code)
+
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "/api/unknown?API_KEY=%5BREDACTED%5D&q=1", records[0].Path)
+		assert.Equal(t, "/api/pets?api_key=%5BREDACTED%5D", records[1].Path)
+		assert.Empty(t, records[1].OperationID)
+	}
+}
+
+func TestAccessLogRedactsPathAndCredentials(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	context := NewContext(doc, api, nil)
+	context.router = DefaultRouter(doc, context.api)
+
+	route, ok := context.router.Lookup("DELETE", "/pets/42")
+	if !assert.True(t, ok) {
+		return
+	}
+	for key, param := range route.Parameters {
+		if param.Name == "id" {
+			param.Extensions = spec.Extensions{}
+			param.Extensions.Add(XSensitive, true)
+			route.Parameters[key] = param
+		}
+	}
+	route.Parameters["X-API-KEY"] = *spec.HeaderParam("X-API-KEY")
+	route.Operation.Security = []map[string][]string{{"apiKey": {}}}
+
+	request, _ := http.NewRequest("DELETE", "/api/pets/42", nil)
+	request.Header.Set("X-API-KEY", "token123")
+	record := &AccessRecord{Path: "/api/pets/42"}
+	context.describeRoute(record, route, request, request.URL.Query())
+
+	assert.Equal(t, "/api/pets/"+Redacted, record.Path)
+	assert.Equal(t, map[string]string{"id": Redacted, "X-API-KEY": Redacted}, record.Params)
+}
+
+func TestAccessLogRedactsCredentialsOfPublicOperation(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	doc.Spec().SecurityDefinitions["queryKey"] = spec.APIKeyAuth("api_key", "query")
+	context := NewContext(doc, api, nil)
+	context.router = DefaultRouter(doc, context.api)
+
+	route, ok := context.router.Lookup("GET", "/pets")
+	if !assert.True(t, ok) {
+		return
+	}
+	route.Operation.Security = nil
+	if !assert.Empty(t, doc.SecurityDefinitionsFor(route.Operation)) {
+		return
+	}
+
+	request, _ := http.NewRequest("GET", "/api/pets?api_key=token123&limit=2", nil)
+	record := &AccessRecord{Path: "/api/pets"}
+	context.describeRoute(record, route, request, request.URL.Query())
+
+	assert.Equal(t, "/api/pets?api_key=%5BREDACTED%5D&limit=2", record.Path)
+	assert.Equal(t, map[string]string{"limit": "2"}, record.Params)
+}
+
+func TestJSONAccessLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := JSONAccessLogger(&buf)
+	logger.LogAccess(&AccessRecord{Method: "GET", Path: "/api/pets", OperationID: "getAllPets", Status: 200})
+	logger.LogAccess(&AccessRecord{Method: "POST", Path: "/api/pets", Status: 201})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if assert.Len(t, lines, 2) {
+		var record map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(lines[0], &record)) {
+			assert.Equal(t, "GET", record["method"])
+			assert.Equal(t, "getAllPets", record["operationId"])
+			assert.EqualValues(t, 200, record["status"])
+			assert.NotContains(t, record, "principal")
+		}
+	}
+}
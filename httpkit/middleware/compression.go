@@ -0,0 +1,309 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-swagger/go-swagger/errors"
+	"github.com/go-swagger/go-swagger/httpkit"
+)
+
+const (
+	// XCompression is the vendor extension an operation uses to opt out of compression with x-compression: false
+	XCompression = "x-compression"
+
+	headerAcceptEncoding  = "Accept-Encoding"
+	headerContentEncoding = "Content-Encoding"
+	headerContentLength   = "Content-Length"
+
+	encodingGzip     = "gzip"
+	encodingDeflate  = "deflate"
+	encodingIdentity = "identity"
+)
+
+// DefaultCompressedTypes are the media types of the responses that are compressed when no others are configured
+var DefaultCompressedTypes = []string{
+	"text/*",
+	"application/json",
+	"application/*+json",
+	"application/xml",
+	"application/*+xml",
+	"application/x-yaml",
+	"application/javascript",
+}
+
+// CompressionOptions configures the compression of responses and the decompression of request bodies
+type CompressionOptions struct {
+	// MinSize is the size in bytes a response body needs to have to get compressed
+	MinSize int
+	// Level is the compression level, the zero value uses the default level
+	Level int
+	// ContentTypes are the media types of the responses that get compressed, they can have wildcards.
+	// When there are none, the DefaultCompressedTypes are compressed.
+	ContentTypes []string
+	// MaxDecompressedSize is the largest size in bytes a compressed request body can have once it's decompressed,
+	// when it's zero or less the size isn't limited
+	MaxDecompressedSize int64
+}
+
+func (o *CompressionOptions) level() int {
+	if o.Level == 0 {
+		return flate.DefaultCompression
+	}
+	return o.Level
+}
+
+func (o *CompressionOptions) compresses(contentType string) bool {
+	allowed := o.ContentTypes
+	if len(allowed) == 0 {
+		allowed = DefaultCompressedTypes
+	}
+	if contentType == "" {
+		return false
+	}
+	_, ok := httpkit.MatchContentType(allowed, contentType)
+	return ok
+}
+
+// newCompression creates a middleware that decompresses request bodies and compresses responses
+func newCompression(ctx *Context, next http.Handler) http.Handler {
+	if ctx.compression == nil {
+		return next
+	}
+	opts := *ctx.compression
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		route, ok := ctx.RouteInfo(r)
+		if ok && route.Operation != nil {
+			if enabled, ok := route.Operation.Extensions.GetBool(XCompression); ok && !enabled {
+				next.ServeHTTP(rw, r)
+				return
+			}
+		}
+
+		if err := decompressRequest(r, opts.MaxDecompressedSize); err != nil {
+			var produces []string
+			if route != nil {
+				produces = route.Produces
+			}
+			ctx.Respond(rw, r, produces, route, err)
+			return
+		}
+
+		httpkit.AddVary(rw.Header(), headerAcceptEncoding)
+		encoding := negotiateEncoding(r.Header.Get(headerAcceptEncoding))
+		if encoding == "" || r.Method == "HEAD" {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		cw := &compressWriter{rw: rw, encoding: encoding, opts: &opts}
+		next.ServeHTTP(cw, r)
+		// a panic leaves the response that was held on to unsent, so the recovery can still send an error
+		cw.Close()
+	})
+}
+
+// decompressRequest replaces the body of a request with a compressed body by a reader that decompresses it
+func decompressRequest(r *http.Request, maxSize int64) error {
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get(headerContentEncoding)))
+	if encoding == "" || encoding == encodingIdentity || r.Body == nil {
+		return nil
+	}
+
+	var body io.ReadCloser
+	switch encoding {
+	case encodingGzip, "x-gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return errors.New(http.StatusBadRequest, "the request body can't be decompressed: %v", err)
+		}
+		body = gz
+	case encodingDeflate:
+		body = flate.NewReader(r.Body)
+	default:
+		return errors.New(http.StatusUnsupportedMediaType, "unsupported content encoding %q, only [gzip deflate] are allowed", encoding)
+	}
+
+	r.Body = &decompressedBody{ReadCloser: body, original: r.Body, remaining: maxSize, limited: maxSize > 0, max: maxSize}
+	r.Header.Del(headerContentEncoding)
+	r.Header.Del(headerContentLength)
+	r.ContentLength = -1
+	return nil
+}
+
+// decompressedBody stops reading a decompressed body when it gets larger than the limit, so a zip bomb can't fill the memory
+type decompressedBody struct {
+	io.ReadCloser
+	original  io.ReadCloser
+	limited   bool
+	remaining int64
+	max       int64
+}
+
+func (d *decompressedBody) Read(p []byte) (int, error) {
+	if !d.limited {
+		return d.ReadCloser.Read(p)
+	}
+	if d.remaining <= 0 {
+		// one more byte tells a body of exactly the maximum size apart from a body that is too large
+		var b [1]byte
+		if n, _ := d.ReadCloser.Read(b[:]); n > 0 {
+			return 0, errors.New(http.StatusRequestEntityTooLarge, "the decompressed request body is larger than %d bytes", d.max)
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > d.remaining {
+		p = p[:d.remaining]
+	}
+	n, err := d.ReadCloser.Read(p)
+	d.remaining -= int64(n)
+	return n, err
+}
+
+func (d *decompressedBody) Close() error {
+	d.ReadCloser.Close()
+	return d.original.Close()
+}
+
+// negotiateEncoding picks the content coding for a response from an Accept-Encoding header, gzip is preferred over deflate
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+	qs := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.TrimSpace(kv[0]) == "q" {
+				v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+		qs[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{encodingGzip, encodingDeflate} {
+		q, ok := qs[coding]
+		if !ok {
+			q, ok = qs["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressWriter holds on to the start of a response until it knows whether the response gets compressed
+type compressWriter struct {
+	rw       http.ResponseWriter
+	encoding string
+	opts     *CompressionOptions
+	code     int
+	buf      []byte
+	started  bool
+	writer   io.WriteCloser
+}
+
+func (c *compressWriter) Header() http.Header {
+	return c.rw.Header()
+}
+
+func (c *compressWriter) WriteHeader(code int) {
+	if c.code == 0 {
+		c.code = code
+	}
+}
+
+func (c *compressWriter) Write(data []byte) (int, error) {
+	if c.code == 0 {
+		c.code = http.StatusOK
+	}
+	if !c.started {
+		c.buf = append(c.buf, data...)
+		if len(c.buf) < c.opts.MinSize {
+			return len(data), nil
+		}
+		if err := c.start(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if c.writer != nil {
+		return c.writer.Write(data)
+	}
+	return c.rw.Write(data)
+}
+
+// start decides whether the response gets compressed, sends the headers and writes what was held on to
+func (c *compressWriter) start() error {
+	c.started = true
+	header := c.rw.Header()
+	compress := len(c.buf) > 0 && len(c.buf) >= c.opts.MinSize &&
+		c.code != http.StatusNoContent && c.code != http.StatusNotModified &&
+		header.Get(headerContentEncoding) == "" &&
+		c.opts.compresses(header.Get(httpkit.HeaderContentType))
+
+	if compress {
+		header.Set(headerContentEncoding, c.encoding)
+		header.Del(headerContentLength)
+		var err error
+		if c.encoding == encodingGzip {
+			c.writer, err = gzip.NewWriterLevel(c.rw, c.opts.level())
+		} else {
+			c.writer, err = flate.NewWriter(c.rw, c.opts.level())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	c.rw.WriteHeader(c.code)
+
+	if len(c.buf) == 0 {
+		return nil
+	}
+	buf := c.buf
+	c.buf = nil
+	if c.writer != nil {
+		_, err := c.writer.Write(buf)
+		return err
+	}
+	_, err := c.rw.Write(buf)
+	return err
+}
+
+// Close sends a response that was too small to decide on and finishes the compressed stream
+func (c *compressWriter) Close() error {
+	if !c.started {
+		if c.code == 0 && len(c.buf) == 0 {
+			return nil
+		}
+		if c.code == 0 {
+			c.code = http.StatusOK
+		}
+		// it's never compressed, it's smaller than the minimum size
+		c.opts = &CompressionOptions{MinSize: len(c.buf) + 1}
+		if err := c.start(); err != nil {
+			return err
+		}
+	}
+	if c.writer != nil {
+		return c.writer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+var largeBody = `{"name":"` + strings.Repeat("a", 2048) + `"}`
+
+func compressionContext(t *testing.T, opts CompressionOptions) *Context {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+	context.SetCompression(opts)
+	return context
+}
+
+func gunzip(t *testing.T, data []byte) string {
+	rdr, err := gzip.NewReader(bytes.NewReader(data))
+	if assert.NoError(t, err) {
+		b, err := ioutil.ReadAll(rdr)
+		assert.NoError(t, err)
+		return string(b)
+	}
+	return ""
+}
+
+func gzipped(data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestCompressResponse(t *testing.T) {
+	context := compressionContext(t, CompressionOptions{MinSize: 1024})
+	mw := newRouter(context, newCompression(context, responder(200, largeBody, nil)))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(headerAcceptEncoding, "gzip, deflate")
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "gzip", recorder.Header().Get(headerContentEncoding))
+	assert.Equal(t, headerAcceptEncoding, recorder.Header().Get(httpkit.HeaderVary))
+	assert.Equal(t, largeBody, gunzip(t, recorder.Body.Bytes()))
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(headerAcceptEncoding, "gzip;q=0.5, deflate")
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, "deflate", recorder.Header().Get(headerContentEncoding))
+	b, err := ioutil.ReadAll(flate.NewReader(recorder.Body))
+	assert.NoError(t, err)
+	assert.Equal(t, largeBody, string(b))
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/pets", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Empty(t, recorder.Header().Get(headerContentEncoding))
+	assert.Equal(t, headerAcceptEncoding, recorder.Header().Get(httpkit.HeaderVary))
+	assert.Equal(t, largeBody, recorder.Body.String())
+}
+
+func TestCompressResponseSkipped(t *testing.T) {
+	context := compressionContext(t, CompressionOptions{MinSize: 1024})
+	small := newRouter(context, newCompression(context, responder(200, `{"name":"Dog"}`, nil)))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(headerAcceptEncoding, "gzip")
+	small.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(headerContentEncoding))
+	assert.Equal(t, `{"name":"Dog"}`, recorder.Body.String())
+
+	binary := newRouter(context, newCompression(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set(httpkit.HeaderContentType, "image/png")
+		rw.Write([]byte(largeBody))
+	})))
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(headerAcceptEncoding, "gzip")
+	binary.ServeHTTP(recorder, request)
+	assert.Empty(t, recorder.Header().Get(headerContentEncoding))
+	assert.Equal(t, largeBody, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(headerAcceptEncoding, "gzip;q=0, identity")
+	newRouter(context, newCompression(context, responder(200, largeBody, nil))).ServeHTTP(recorder, request)
+	assert.Empty(t, recorder.Header().Get(headerContentEncoding))
+	assert.Equal(t, largeBody, recorder.Body.String())
+}
+
+func TestCompressionOptOut(t *testing.T) {
+	context := compressionContext(t, CompressionOptions{})
+	op, ok := context.spec.OperationFor("GET", "/pets")
+	if assert.True(t, ok) {
+		op.Extensions = spec.Extensions{}
+		op.Extensions.Add(XCompression, false)
+	}
+	mw := newRouter(context, newCompression(context, responder(200, largeBody, nil)))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(headerAcceptEncoding, "gzip")
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(headerContentEncoding))
+	assert.Equal(t, largeBody, recorder.Body.String())
+}
+
+func TestDecompressRequest(t *testing.T) {
+	var received string
+	echo := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received = string(b)
+		assert.Empty(t, r.Header.Get(headerContentEncoding))
+		rw.WriteHeader(http.StatusCreated)
+	})
+	context := compressionContext(t, CompressionOptions{MaxDecompressedSize: 4096})
+	mw := newRouter(context, newCompression(context, echo))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/pets", bytes.NewReader(gzipped(largeBody)))
+	request.Header.Set(httpkit.HeaderContentType, httpkit.JSONMime)
+	request.Header.Set(headerContentEncoding, "gzip")
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, largeBody, received)
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("POST", "/api/pets", strings.NewReader(largeBody))
+	request.Header.Set(httpkit.HeaderContentType, httpkit.JSONMime)
+	request.Header.Set(headerContentEncoding, "br")
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("POST", "/api/pets", strings.NewReader(largeBody))
+	request.Header.Set(httpkit.HeaderContentType, httpkit.JSONMime)
+	request.Header.Set(headerContentEncoding, "gzip")
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestDecompressRequestTooLarge(t *testing.T) {
+	context := compressionContext(t, CompressionOptions{MaxDecompressedSize: 1024})
+	handler := context.APIHandler()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/pets", bytes.NewReader(gzipped(largeBody)))
+	request.Header.Set(httpkit.HeaderContentType, httpkit.JSONMime)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	request.Header.Set(headerContentEncoding, "gzip")
+	request.SetBasicAuth("admin", "admin")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	data := []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"*", "gzip"},
+		{"*;q=0.5, deflate", "deflate"},
+		{"br, identity", ""},
+	}
+	for _, v := range data {
+		assert.Equal(t, v.expected, negotiateEncoding(v.header), "accept-encoding: %q", v.header)
+	}
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-swagger/go-swagger/errors"
+	"github.com/go-swagger/go-swagger/httpkit"
+)
+
+const (
+	// XPreconditions is the vendor extension that marks an operation whose If-Match and If-Unmodified-Since
+	// preconditions are enforced with x-preconditions: true
+	XPreconditions = "x-preconditions"
+
+	headerETag              = "ETag"
+	headerLastModified      = "Last-Modified"
+	headerIfMatch           = "If-Match"
+	headerIfNoneMatch       = "If-None-Match"
+	headerIfModifiedSince   = "If-Modified-Since"
+	headerIfUnmodifiedSince = "If-Unmodified-Since"
+)
+
+// ResourceStateFunc returns the current entity tag and modification time of the resource a request is for,
+// an empty entity tag means the resource doesn't exist and a zero time means the modification time is unknown
+type ResourceStateFunc func(r *http.Request, route *MatchedRoute) (etag string, lastModified time.Time, err error)
+
+// ConditionalOptions configures how conditional requests are answered
+type ConditionalOptions struct {
+	// GenerateETags computes a weak entity tag from the body of a successful GET response that has none
+	GenerateETags bool
+	// ResourceState provides the state the preconditions of the operations marked with x-preconditions are checked against
+	ResourceState ResourceStateFunc
+}
+
+// newConditional creates a middleware that answers conditional requests, a GET or HEAD request for a representation
+// the client already has gets a 304 Not Modified and a request for a marked operation with a failed precondition gets a 412
+func newConditional(ctx *Context, next http.Handler) http.Handler {
+	if ctx.conditional == nil {
+		return next
+	}
+	opts := *ctx.conditional
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		route, _ := ctx.RouteInfo(r)
+
+		if route != nil && route.Operation != nil && opts.ResourceState != nil {
+			if enabled, ok := route.Operation.Extensions.GetBool(XPreconditions); ok && enabled {
+				etag, lastModified, err := opts.ResourceState(r, route)
+				if err == nil {
+					err = checkPreconditions(r, etag, lastModified)
+				}
+				if err != nil {
+					ctx.Respond(rw, r, route.Produces, route, err)
+					return
+				}
+			}
+		}
+
+		if r.Method != "GET" && r.Method != "HEAD" {
+			next.ServeHTTP(rw, r)
+			return
+		}
+		// the response only needs to be held back when it might become a 304 or gets an entity tag from its body
+		if !opts.GenerateETags && r.Header.Get(headerIfNoneMatch) == "" && r.Header.Get(headerIfModifiedSince) == "" {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		buf := newBufferedResponse()
+		next.ServeHTTP(buf, r)
+		if buf.code == 0 {
+			buf.code = http.StatusOK
+		}
+
+		if buf.code == http.StatusOK {
+			if buf.header.Get(headerETag) == "" && opts.GenerateETags && buf.body.Len() > 0 {
+				buf.header.Set(headerETag, fmt.Sprintf(`W/"%x"`, sha1.Sum(buf.body.Bytes())))
+			}
+			if notModified(r, buf.header) {
+				writeNotModified(rw, buf.header)
+				return
+			}
+		}
+		buf.flush(rw)
+	})
+}
+
+// checkPreconditions evaluates the If-Match, If-Unmodified-Since and If-None-Match headers of a request
+// that changes a resource against the current state of the resource
+func checkPreconditions(r *http.Request, etag string, lastModified time.Time) error {
+	if ifMatch := r.Header.Get(headerIfMatch); ifMatch != "" {
+		if !matchETag(ifMatch, etag, false) {
+			return errors.New(http.StatusPreconditionFailed, "the resource doesn't match %s", ifMatch)
+		}
+	} else if since, err := http.ParseTime(r.Header.Get(headerIfUnmodifiedSince)); err == nil && !lastModified.IsZero() {
+		if lastModified.Truncate(time.Second).After(since) {
+			return errors.New(http.StatusPreconditionFailed, "the resource was modified after %s", r.Header.Get(headerIfUnmodifiedSince))
+		}
+	}
+
+	if ifNoneMatch := r.Header.Get(headerIfNoneMatch); ifNoneMatch != "" && r.Method != "GET" && r.Method != "HEAD" {
+		if matchETag(ifNoneMatch, etag, true) {
+			return errors.New(http.StatusPreconditionFailed, "the resource matches %s", ifNoneMatch)
+		}
+	}
+	return nil
+}
+
+// notModified returns true when the representation in a response is the one the client already has
+func notModified(r *http.Request, header http.Header) bool {
+	if ifNoneMatch := r.Header.Get(headerIfNoneMatch); ifNoneMatch != "" {
+		return matchETag(ifNoneMatch, header.Get(headerETag), true)
+	}
+	since, err := http.ParseTime(r.Header.Get(headerIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(header.Get(headerLastModified))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
+
+// writeNotModified sends a 304 with the headers of the response that describe the representation without its body
+func writeNotModified(rw http.ResponseWriter, header http.Header) {
+	for k, v := range header {
+		switch k {
+		case httpkit.HeaderContentType, headerContentLength:
+			continue
+		}
+		rw.Header()[k] = v
+	}
+	rw.WriteHeader(http.StatusNotModified)
+}
+
+// matchETag returns true when an entity tag is in the list of a If-Match or If-None-Match header.
+// The weak comparison ignores the W/ prefix, the strong comparison never matches a weak entity tag.
+func matchETag(list, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	if !weak && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+var petModified = time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+
+func conditionalContext(t *testing.T, opts ConditionalOptions) *Context {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+	context.SetConditionalRequests(opts)
+	return context
+}
+
+func conditionalRequest(method, path string, headers map[string]string) *http.Request {
+	request, _ := http.NewRequest(method, path, nil)
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+	return request
+}
+
+func TestConditionalGeneratedETag(t *testing.T) {
+	context := conditionalContext(t, ConditionalOptions{GenerateETags: true})
+	mw := newRouter(context, newConditional(context, responder(200, `{"id":1,"name":"Dog"}`, nil)))
+
+	recorder := httptest.NewRecorder()
+	mw.ServeHTTP(recorder, conditionalRequest("GET", "/api/pets/1", nil))
+	assert.Equal(t, 200, recorder.Code)
+	etag := recorder.Header().Get(headerETag)
+	assert.Contains(t, etag, `W/"`)
+	assert.Equal(t, `{"id":1,"name":"Dog"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	mw.ServeHTTP(recorder, conditionalRequest("GET", "/api/pets/1", map[string]string{headerIfNoneMatch: `"other", ` + etag}))
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Equal(t, etag, recorder.Header().Get(headerETag))
+	assert.Empty(t, recorder.Header().Get(httpkit.HeaderContentType))
+	assert.Empty(t, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	mw.ServeHTTP(recorder, conditionalRequest("GET", "/api/pets/1", map[string]string{headerIfNoneMatch: `W/"other"`}))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, `{"id":1,"name":"Dog"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	mw = newRouter(context, newConditional(context, responder(404, `{"code":404,"message":"not found"}`, nil)))
+	mw.ServeHTTP(recorder, conditionalRequest("GET", "/api/pets/1", map[string]string{headerIfNoneMatch: "*"}))
+	assert.Equal(t, 404, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(headerETag))
+}
+
+func TestConditionalHandlerETag(t *testing.T) {
+	context := conditionalContext(t, ConditionalOptions{})
+	headers := map[string]string{headerETag: `"v2"`, headerLastModified: petModified.Format(http.TimeFormat)}
+	mw := newRouter(context, newConditional(context, responder(200, `{"id":1,"name":"Dog"}`, headers)))
+
+	recorder := httptest.NewRecorder()
+	mw.ServeHTTP(recorder, conditionalRequest("GET", "/api/pets/1", map[string]string{headerIfNoneMatch: `W/"v2"`}))
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Equal(t, `"v2"`, recorder.Header().Get(headerETag))
+
+	recorder = httptest.NewRecorder()
+	mw.ServeHTTP(recorder, conditionalRequest("GET", "/api/pets/1", map[string]string{headerIfModifiedSince: petModified.Add(time.Hour).Format(http.TimeFormat)}))
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	mw.ServeHTTP(recorder, conditionalRequest("GET", "/api/pets/1", map[string]string{headerIfModifiedSince: petModified.Add(-time.Hour).Format(http.TimeFormat)}))
+	assert.Equal(t, 200, recorder.Code)
+
+	// If-None-Match takes precedence over If-Modified-Since
+	recorder = httptest.NewRecorder()
+	mw.ServeHTTP(recorder, conditionalRequest("GET", "/api/pets/1", map[string]string{
+		headerIfNoneMatch:     `"v1"`,
+		headerIfModifiedSince: petModified.Add(time.Hour).Format(http.TimeFormat),
+	}))
+	assert.Equal(t, 200, recorder.Code)
+}
+
+func TestConditionalStreamsUnconditionalRequests(t *testing.T) {
+	context := conditionalContext(t, ConditionalOptions{})
+	var writer http.ResponseWriter
+	mw := newRouter(context, newConditional(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		writer = rw
+		rw.WriteHeader(http.StatusOK)
+	})))
+
+	recorder := httptest.NewRecorder()
+	mw.ServeHTTP(recorder, conditionalRequest("GET", "/api/pets/1", nil))
+	assert.Equal(t, recorder, writer)
+
+	// a request with a condition is buffered to be able to answer it with a 304
+	mw.ServeHTTP(httptest.NewRecorder(), conditionalRequest("GET", "/api/pets/1", map[string]string{headerIfNoneMatch: `"v1"`}))
+	assert.IsType(t, new(bufferedResponse), writer)
+
+	context = conditionalContext(t, ConditionalOptions{GenerateETags: true})
+	mw = newRouter(context, newConditional(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		writer = rw
+	})))
+	mw.ServeHTTP(httptest.NewRecorder(), conditionalRequest("GET", "/api/pets/1", nil))
+	assert.IsType(t, new(bufferedResponse), writer)
+}
+
+func TestConditionalPreconditions(t *testing.T) {
+	context := conditionalContext(t, ConditionalOptions{
+		ResourceState: func(r *http.Request, route *MatchedRoute) (string, time.Time, error) {
+			assert.Equal(t, "deletePet", route.Operation.ID)
+			return `"v2"`, petModified, nil
+		},
+	})
+	op, ok := context.spec.OperationFor("DELETE", "/pets/{id}")
+	if assert.True(t, ok) {
+		op.Extensions = spec.Extensions{}
+		op.Extensions.Add(XPreconditions, true)
+	}
+	mw := newRouter(context, newConditional(context, responder(204, "", nil)))
+
+	data := []struct {
+		headers  map[string]string
+		expected int
+	}{
+		{nil, 204},
+		{map[string]string{headerIfMatch: `"v2"`}, 204},
+		{map[string]string{headerIfMatch: `"v1", "v2"`}, 204},
+		{map[string]string{headerIfMatch: "*"}, 204},
+		{map[string]string{headerIfMatch: `"v1"`}, http.StatusPreconditionFailed},
+		{map[string]string{headerIfMatch: `W/"v2"`}, http.StatusPreconditionFailed},
+		{map[string]string{headerIfUnmodifiedSince: petModified.Format(http.TimeFormat)}, 204},
+		{map[string]string{headerIfUnmodifiedSince: petModified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusPreconditionFailed},
+		{map[string]string{headerIfMatch: `"v2"`, headerIfUnmodifiedSince: petModified.Add(-time.Hour).Format(http.TimeFormat)}, 204},
+		{map[string]string{headerIfNoneMatch: "*"}, http.StatusPreconditionFailed},
+		{map[string]string{headerIfNoneMatch: `"v1"`}, 204},
+	}
+	for _, v := range data {
+		recorder := httptest.NewRecorder()
+		mw.ServeHTTP(recorder, conditionalRequest("DELETE", "/api/pets/1", v.headers))
+		assert.Equal(t, v.expected, recorder.Code, "headers: %v", v.headers)
+	}
+
+	// the operations that aren't marked don't check their preconditions
+	recorder := httptest.NewRecorder()
+	newRouter(context, newConditional(context, responder(201, "", nil))).ServeHTTP(recorder, conditionalRequest("POST", "/api/pets", map[string]string{headerIfMatch: `"v1"`}))
+	assert.Equal(t, 201, recorder.Code)
+}
+
+func TestMatchETag(t *testing.T) {
+	assert.True(t, matchETag(`"a"`, `"a"`, false))
+	assert.True(t, matchETag(`"b", "a"`, `"a"`, false))
+	assert.False(t, matchETag(`W/"a"`, `"a"`, false))
+	assert.False(t, matchETag(`"a"`, `W/"a"`, false))
+	assert.True(t, matchETag(`W/"a"`, `"a"`, true))
+	assert.True(t, matchETag(`"a"`, `W/"a"`, true))
+	assert.True(t, matchETag("*", `"a"`, false))
+	assert.False(t, matchETag("*", "", true))
+	assert.False(t, matchETag(`"a"`, `"b"`, true))
+}
@@ -2,14 +2,13 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/go-swagger/go-swagger/errors"
 	"github.com/go-swagger/go-swagger/httpkit"
 	"github.com/go-swagger/go-swagger/httpkit/middleware/untyped"
 	"github.com/go-swagger/go-swagger/spec"
 	"github.com/go-swagger/go-swagger/strfmt"
-	"github.com/golang/gddo/httputil"
-	"github.com/gorilla/context"
 )
 
 // RequestBinder is an interface for types to implement
@@ -19,12 +18,26 @@ type RequestBinder interface {
 }
 
 // Context is a type safe wrapper around an untyped request context
-// used throughout to store request context with the gorilla context module
+// used throughout to store request context with the gorilla context module.
+// The Set methods configure the API handler, so they need to be called before it's created.
 type Context struct {
-	spec    *spec.Document
-	api     RoutableAPI
-	router  Router
-	formats strfmt.Registry
+	spec     *spec.Document
+	api      RoutableAPI
+	router   Router
+	formats  strfmt.Registry
+	readOnly ReadOnlyPolicy
+
+	responseValidation ResponseValidationMode
+	routerFactory      RouterFactory
+	compression        *CompressionOptions
+	conditional        *ConditionalOptions
+	cors               *CORSOptions
+	describeOptions    bool
+	metrics            *Metrics
+	accessLogger       AccessLogger
+	recoveryHook       RecoveryHook
+	rateLimiting       *RateLimitOptions
+	maxBodySize        int64
 }
 
 type routableUntypedAPI struct {
@@ -117,13 +130,13 @@ func (r *routableUntypedAPI) DefaultConsumes() string {
 
 // NewRoutableContext creates a new context for a routable API
 func NewRoutableContext(spec *spec.Document, routableAPI RoutableAPI, routes Router) *Context {
-	ctx := &Context{spec: spec, api: routableAPI}
+	ctx := &Context{spec: spec, api: routableAPI, router: routes}
 	return ctx
 }
 
 // NewContext creates a new context wrapper
 func NewContext(spec *spec.Document, api *untyped.API, routes Router) *Context {
-	ctx := &Context{spec: spec}
+	ctx := &Context{spec: spec, router: routes}
 	ctx.api = newRoutableUntypedAPI(spec, api, ctx)
 	return ctx
 }
@@ -146,6 +159,7 @@ const (
 	ctxSecurityPrincipal
 
 	ctxConsumer
+	ctxRequestValues
 )
 
 type contentTypeValue struct {
@@ -153,6 +167,69 @@ type contentTypeValue struct {
 	Charset   string
 }
 
+// SetReadOnlyPolicy configures what happens with read only properties that are sent in a request body
+func (c *Context) SetReadOnlyPolicy(policy ReadOnlyPolicy) {
+	c.readOnly = policy
+}
+
+// SetRouterFactory configures how the router is created when the context has no router
+func (c *Context) SetRouterFactory(factory RouterFactory) {
+	c.routerFactory = factory
+}
+
+// SetResponseValidation configures the validation of the responses against the spec
+func (c *Context) SetResponseValidation(mode ResponseValidationMode) {
+	c.responseValidation = mode
+}
+
+// SetCompression enables the compression of responses and the decompression of request bodies
+func (c *Context) SetCompression(opts CompressionOptions) {
+	c.compression = &opts
+}
+
+// SetConditionalRequests enables the answers to conditional requests with 304 Not Modified and 412 Precondition Failed
+func (c *Context) SetConditionalRequests(opts ConditionalOptions) {
+	c.conditional = &opts
+}
+
+// SetCORS enables the cross origin resource sharing of the API for the allowed origins
+func (c *Context) SetCORS(opts CORSOptions) {
+	c.cors = &opts
+}
+
+// SetDescribeOptions configures whether the answers to OPTIONS requests have the summaries of the operations of a path
+// in a JSON body, without them the answer only has the Allow header
+func (c *Context) SetDescribeOptions(enabled bool) {
+	c.describeOptions = enabled
+}
+
+// SetMetrics configures the collector of the metrics of the requests for the operations of the API
+func (c *Context) SetMetrics(metrics *Metrics) {
+	c.metrics = metrics
+}
+
+// SetAccessLogger configures the logger that gets a record for every request that is served by the API
+func (c *Context) SetAccessLogger(logger AccessLogger) {
+	c.accessLogger = logger
+}
+
+// SetRecoveryHook configures the hook that gets the panics that are recovered while requests are served,
+// without a hook they are written to the standard logger
+func (c *Context) SetRecoveryHook(hook RecoveryHook) {
+	c.recoveryHook = hook
+}
+
+// SetRateLimiting enables the rate limits and concurrency limits that are declared in the spec
+func (c *Context) SetRateLimiting(opts RateLimitOptions) {
+	c.rateLimiting = &opts
+}
+
+// SetMaxBodySize configures the largest request body in bytes for the operations without x-max-body-size,
+// a larger body gets a 413
+func (c *Context) SetMaxBodySize(size int64) {
+	c.maxBodySize = size
+}
+
 // BasePath returns the base path for this API
 func (c *Context) BasePath() string {
 	return c.spec.BasePath()
@@ -170,11 +247,11 @@ func (c *Context) BindValidRequest(request *http.Request, route *MatchedRoute, b
 
 	// check and validate content type, select consumer
 	if httpkit.CanHaveBody(request.Method) {
-		ct, _, err := httpkit.ContentType(request.Header)
-		if err != nil {
+		if _, _, err := httpkit.ContentType(request.Header); err != nil {
 			res = append(res, err)
 		} else {
-			if err := validateContentType(route.Consumes, ct); err != nil {
+			ct, err := validateContentType(route.Consumes, requestContentType(request))
+			if err != nil {
 				res = append(res, err)
 			}
 			route.Consumer = route.Consumers[ct]
@@ -183,7 +260,7 @@ func (c *Context) BindValidRequest(request *http.Request, route *MatchedRoute, b
 
 	// check and validate the response format
 	if len(res) == 0 {
-		if str := httputil.NegotiateContentType(request, route.Produces, ""); str == "" {
+		if str := httpkit.NegotiateContentType(request, route.Produces, ""); str == "" {
 			res = append(res, errors.InvalidResponseFormat(request.Header.Get(httpkit.HeaderAccept), route.Produces))
 		}
 	}
@@ -198,6 +275,7 @@ func (c *Context) BindValidRequest(request *http.Request, route *MatchedRoute, b
 	}
 
 	if len(res) > 0 {
+		c.countFailure(request, route, false)
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
@@ -205,17 +283,15 @@ func (c *Context) BindValidRequest(request *http.Request, route *MatchedRoute, b
 
 // ContentType gets the parsed value of a content type
 func (c *Context) ContentType(request *http.Request) (string, string, *errors.ParseError) {
-	if v, ok := context.GetOk(request, ctxContentType); ok {
-		if val, ok := v.(*contentTypeValue); ok {
-			return val.MediaType, val.Charset, nil
-		}
+	if mt, cs, ok := ContentTypeFrom(request.Context()); ok {
+		return mt, cs, nil
 	}
 
 	mt, cs, err := httpkit.ContentType(request.Header)
 	if err != nil {
 		return "", "", err
 	}
-	context.Set(request, ctxContentType, &contentTypeValue{mt, cs})
+	setRequestValue(request, ctxContentType, &contentTypeValue{mt, cs})
 	return mt, cs, nil
 }
 
@@ -232,16 +308,13 @@ func (c *Context) RouteInfo(request *http.Request) (*MatchedRoute, bool) {
 
 
 
-	v, ok := context.GetOk(request, ctxMatchedRoute)
-	if ok {
-		if val, ok := v.(*MatchedRoute); ok {
-			return val, ok
-		}
+	if route, ok := MatchedRouteFrom(request.Context()); ok {
+		return route, ok
 	}
 
 	route, ok := c.LookupRoute(request)
 	if ok {
-		context.Set(request, ctxMatchedRoute, route)
+		setRequestValue(request, ctxMatchedRoute, route)
 		return route, ok
 	}
 
@@ -250,15 +323,13 @@ func (c *Context) RouteInfo(request *http.Request) (*MatchedRoute, bool) {
 
 // ResponseFormat negotiates the response content type
 func (c *Context) ResponseFormat(r *http.Request, offers []string) string {
-	if v, ok := context.GetOk(r, ctxResponseFormat); ok {
-		if val, ok := v.(string); ok {
-			return val
-		}
+	if format, ok := ResponseFormatFrom(r.Context()); ok {
+		return format
 	}
 
-	format := httputil.NegotiateContentType(r, offers, "")
+	format := httpkit.NegotiateContentType(r, offers, "")
 	if format != "" {
-		context.Set(r, ctxResponseFormat, format)
+		setRequestValue(r, ctxResponseFormat, format)
 	}
 	return format
 }
@@ -270,10 +341,18 @@ func (c *Context) AllowedMethods(request *http.Request) []string {
 
 // Authorize authorizes the request
 func (c *Context) Authorize(request *http.Request, route *MatchedRoute) (interface{}, error) {
+	principal, err := c.authenticate(request, route)
+	if err != nil {
+		c.countFailure(request, route, true)
+	}
+	return principal, err
+}
+
+func (c *Context) authenticate(request *http.Request, route *MatchedRoute) (interface{}, error) {
 	if len(route.Authenticators) == 0 {
 		return nil, nil
 	}
-	if v, ok := context.GetOk(request, ctxSecurityPrincipal); ok {
+	if v, ok := SecurityPrincipalFrom(request.Context()); ok {
 		return v, nil
 	}
 
@@ -282,7 +361,7 @@ func (c *Context) Authorize(request *http.Request, route *MatchedRoute) (interfa
 		if !applies || err != nil || usr == nil {
 			continue
 		}
-		context.Set(request, ctxSecurityPrincipal, usr)
+		setRequestValue(request, ctxSecurityPrincipal, usr)
 		return usr, nil
 	}
 
@@ -291,7 +370,7 @@ func (c *Context) Authorize(request *http.Request, route *MatchedRoute) (interfa
 
 // BindAndValidate binds and validates the request
 func (c *Context) BindAndValidate(request *http.Request, matched *MatchedRoute) (interface{}, error) {
-	if v, ok := context.GetOk(request, ctxBoundParams); ok {
+	if v, ok := getRequestValue(request.Context(), ctxBoundParams); ok {
 		if val, ok := v.(*validation); ok {
 			if len(val.result) > 0 {
 				return val.bound, errors.CompositeValidationError(val.result...)
@@ -301,9 +380,10 @@ func (c *Context) BindAndValidate(request *http.Request, matched *MatchedRoute)
 	}
 	result := validateRequest(c, request, matched)
 	if result != nil {
-		context.Set(request, ctxBoundParams, result)
+		setRequestValue(request, ctxBoundParams, result)
 	}
 	if len(result.result) > 0 {
+		c.countFailure(request, matched, false)
 		return result.bound, errors.CompositeValidationError(result.result...)
 	}
 	return result.bound, nil
@@ -327,6 +407,12 @@ func (c *Context) Respond(rw http.ResponseWriter, r *http.Request, produces []st
 
 	format := c.ResponseFormat(r, offers)
 	rw.Header().Set(httpkit.HeaderContentType, format)
+	httpkit.AddVary(rw.Header(), httpkit.HeaderAccept)
+
+	if _, ok := data.(error); !ok && format == "" {
+		// nothing the client accepts can be produced, the error tells it what is available
+		data = errors.InvalidResponseFormat(r.Header.Get(httpkit.HeaderAccept), offers)
+	}
 
 	if err, ok := data.(error); ok {
 		if format == "" {
@@ -339,42 +425,90 @@ func (c *Context) Respond(rw http.ResponseWriter, r *http.Request, produces []st
 		c.api.ServeErrorFor(route.Operation.ID)(rw, r, err)
 		return
 	}
-	if route == nil || route.Operation == nil {
-		rw.WriteHeader(200)
-		if r.Method == "HEAD" {
+	code, payload := 0, data
+	var headers http.Header
+	if res, ok := data.(*httpkit.Response); ok && res != nil {
+		code, payload, headers = res.Code, res.Payload, res.Headers
+	}
+
+	producers := c.api.ProducersFor(offers)
+	var response *spec.Response
+	if route != nil && route.Operation != nil {
+		producers = route.Producers
+		res, documented, err := c.documentedResponse(route.Operation, code)
+		if err != nil {
+			c.api.ServeErrorFor(route.Operation.ID)(rw, r, err)
 			return
 		}
-		producers := c.api.ProducersFor(offers)
-		prod, ok := producers[format]
-		if !ok {
-			panic(errors.New(http.StatusInternalServerError, "can't find a producer for "+format))
-		}
-		if err := prod.Produce(rw, data); err != nil {
-			panic(err) // let the recovery middleware deal with this
-		}
+		response, code = res, documented
+	}
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	for k, v := range headers {
+		rw.Header()[k] = v
+	}
+	rw.WriteHeader(code)
+	// a response without a schema and without a payload has no body
+	if code == http.StatusNoContent || code == http.StatusNotModified || r.Method == "HEAD" || (payload == nil && response != nil && response.Schema == nil) {
 		return
 	}
-      			
-	if _, code, ok := route.Operation.SuccessResponse(); ok {
-		rw.WriteHeader(code)
-		if code == 201 || code == 204 || r.Method == "HEAD" {
-			return
-		}
 
-		producers := route.Producers
-		prod, ok := producers[format]
+	prod, ok := producers[format]
+	if !ok {
+		panic(errors.New(http.StatusInternalServerError, "can't find a producer for "+format))
+	}
+	if err := prod.Produce(rw, payload); err != nil {
+		panic(err) // let the recovery middleware deal with this
+	}
+}
+
+// documentedResponse returns the response of an operation for a status code,
+// without a status code it's the success response of the operation
+func (c *Context) documentedResponse(op *spec.Operation, code int) (*spec.Response, int, error) {
+	if code == 0 {
+		response, code, ok := op.SuccessResponse()
 		if !ok {
-			panic(errors.New(http.StatusInternalServerError, "can't find a producer for "+format))
+			return nil, 0, errors.New(http.StatusInternalServerError, "can't produce response")
 		}
-		if err := prod.Produce(rw, data); err != nil {
-			panic(err) // let the recovery middleware deal with this
+		if resolved, ok := c.responseFor(op.Responses, code); ok {
+			return &resolved, code, nil
 		}
-		return
+		return response, code, nil
 	}
-	c.api.ServeErrorFor(route.Operation.ID)(rw, r, errors.New(http.StatusInternalServerError, "can't produce response"))
+	if op.Responses == nil {
+		return nil, 0, errors.New(http.StatusInternalServerError, "status code %d is not declared for %s", code, op.ID)
+	}
+	response, ok := c.responseFor(op.Responses, code)
+	if !ok {
+		return nil, 0, errors.New(http.StatusInternalServerError, "status code %d is not declared for %s", code, op.ID)
+	}
+	return &response, code, nil
 }
 
 // APIHandler returns a handler to serve
 func (c *Context) APIHandler() http.Handler {
-	return specMiddleware(c, newRouter(c, newOperationExecutor(c)))
+	// the router is created first, the CORS middleware uses it to answer preflight requests
+	router := newRouter(c, newMetrics(c, newRateLimit(c, newRequestLimits(c, newCompression(c, newConditional(c, newResponseValidation(c, newOperationExecutor(c))))))))
+	return newAccessLog(c, newRecovery(c, newCORS(c, specMiddleware(c, router))))
+}
+
+// Mount registers the API handler on a serve mux for the paths of the API, so it can be served next to other handlers.
+// A path with parameters is registered as the subtree of its static prefix, the router of the API matches the rest.
+func (c *Context) Mount(mux *http.ServeMux) {
+	handler := c.APIHandler()
+	basePath := strings.TrimSuffix(c.spec.BasePath(), "/")
+
+	mounted := make(map[string]bool)
+	for path := range c.spec.AllPaths() {
+		pattern := basePath + path
+		if idx := strings.Index(pattern, "{"); idx >= 0 {
+			pattern = pattern[:strings.LastIndex(pattern[:idx], "/")+1]
+		}
+		if !mounted[pattern] {
+			mounted[pattern] = true
+			mux.Handle(pattern, handler)
+		}
+	}
 }
@@ -1,14 +1,16 @@
 package middleware
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-swagger/go-swagger/httpkit"
 	"github.com/go-swagger/go-swagger/internal/testing/petstore"
-	"github.com/gorilla/context"
+	"github.com/go-swagger/go-swagger/spec"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,8 +40,9 @@ func TestContextAuthorize(t *testing.T) {
 	ctx.router = DefaultRouter(spec, ctx.api)
 
 	request, _ := httpkit.JSONRequest("GET", "/pets", nil)
+	request = WithRequestValues(request)
 
-	v, ok := context.GetOk(request, ctxSecurityPrincipal)
+	v, ok := SecurityPrincipalFrom(request.Context())
 	assert.False(t, ok)
 	assert.Nil(t, v)
 
@@ -49,7 +52,7 @@ func TestContextAuthorize(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, p)
 
-	v, ok = context.GetOk(request, ctxSecurityPrincipal)
+	v, ok = SecurityPrincipalFrom(request.Context())
 	assert.False(t, ok)
 	assert.Nil(t, v)
 
@@ -58,7 +61,7 @@ func TestContextAuthorize(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, p)
 
-	v, ok = context.GetOk(request, ctxSecurityPrincipal)
+	v, ok = SecurityPrincipalFrom(request.Context())
 	assert.False(t, ok)
 	assert.Nil(t, v)
 
@@ -67,7 +70,7 @@ func TestContextAuthorize(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "admin", p)
 
-	v, ok = context.GetOk(request, ctxSecurityPrincipal)
+	v, ok = SecurityPrincipalFrom(request.Context())
 	assert.True(t, ok)
 	assert.Equal(t, "admin", v)
 
@@ -83,10 +86,11 @@ func TestContextBindAndValidate(t *testing.T) {
 	ctx.router = DefaultRouter(spec, ctx.api)
 
 	request, _ := http.NewRequest("POST", "/pets", nil)
+	request = WithRequestValues(request)
 	request.Header.Add("Accept", "*/*")
 	request.Header.Add("content-type", "text/html")
 
-	v, ok := context.GetOk(request, ctxBoundParams)
+	v, ok := getRequestValue(request.Context(), ctxBoundParams)
 	assert.False(t, ok)
 	assert.Nil(t, v)
 
@@ -95,7 +99,7 @@ func TestContextBindAndValidate(t *testing.T) {
 	assert.NotNil(t, data)
 	assert.NotNil(t, result)
 
-	v, ok = context.GetOk(request, ctxBoundParams)
+	v, ok = getRequestValue(request.Context(), ctxBoundParams)
 	assert.True(t, ok)
 	assert.NotNil(t, v)
 
@@ -131,7 +135,10 @@ func TestContextRender(t *testing.T) {
 
 	recorder = httptest.NewRecorder()
 	request, _ = http.NewRequest("GET", "pets", nil)
-	assert.Panics(t, func() { ctx.Respond(recorder, request, []string{}, ri, map[string]interface{}{"name": "hello"}) })
+	ctx.Respond(recorder, request, []string{}, ri, map[string]interface{}{"name": "hello"})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, ct, recorder.Header().Get(httpkit.HeaderContentType))
+	assert.Equal(t, httpkit.HeaderAccept, recorder.Header().Get(httpkit.HeaderVary))
 
 	request, _ = http.NewRequest("GET", "/pets", nil)
 	request.Header.Set(httpkit.HeaderAccept, ct)
@@ -151,7 +158,18 @@ func TestContextRender(t *testing.T) {
 
 	recorder = httptest.NewRecorder()
 	request, _ = http.NewRequest("GET", "/pets", nil)
-	assert.Panics(t, func() { ctx.Respond(recorder, request, []string{}, ri, map[string]interface{}{"name": "hello"}) })
+	ctx.Respond(recorder, request, []string{}, ri, map[string]interface{}{"name": "hello"})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, ct, recorder.Header().Get(httpkit.HeaderContentType))
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, "application/sgml")
+	ctx.Respond(recorder, request, []string{ct}, ri, map[string]interface{}{"name": "hello"})
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+	assert.Equal(t, ct, recorder.Header().Get(httpkit.HeaderContentType))
+	assert.Equal(t, httpkit.HeaderAccept, recorder.Header().Get(httpkit.HeaderVary))
+	assert.Contains(t, recorder.Body.String(), ct)
 
 	recorder = httptest.NewRecorder()
 	request, _ = http.NewRequest("DELETE", "/pets/1", nil)
@@ -160,6 +178,54 @@ func TestContextRender(t *testing.T) {
 	assert.Equal(t, 204, recorder.Code)
 }
 
+func TestContextRespondWithResponse(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	op := doc.Spec().Paths.Paths["/pets"].Post
+	var created spec.Response
+	err := json.Unmarshal([]byte(`{"description":"created","schema":{"$ref":"#/definitions/pet"}}`), &created)
+	assert.NoError(t, err)
+	op.Responses.StatusCodeResponses[201] = created
+
+	var result interface{}
+	api.RegisterOperation("createPet", httpkit.OperationHandlerFunc(func(params interface{}) (interface{}, error) {
+		return result, nil
+	}))
+	ctx := NewContext(doc, api, nil)
+	handler := ctx.APIHandler()
+
+	serve := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/api/pets", strings.NewReader(`{"name":"fido"}`))
+		request.Header.Set(httpkit.HeaderContentType, httpkit.JSONMime)
+		request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+		request.SetBasicAuth("admin", "admin")
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	result = httpkit.NewResponse(201, map[string]interface{}{"id": 1, "name": "fido"}).WithHeader("Location", "/api/pets/1")
+	recorder := serve()
+	assert.Equal(t, 201, recorder.Code)
+	assert.Equal(t, "/api/pets/1", recorder.Header().Get("Location"))
+	assert.JSONEq(t, `{"id":1,"name":"fido"}`, recorder.Body.String())
+
+	// without a status code it's the lowest documented success code
+	result = &httpkit.Response{Payload: map[string]interface{}{"id": 1, "name": "fido"}}
+	recorder = serve()
+	assert.Equal(t, 200, recorder.Code)
+	assert.JSONEq(t, `{"id":1,"name":"fido"}`, recorder.Body.String())
+
+	// the default response documents every other status code
+	result = httpkit.NewResponse(409, map[string]interface{}{"code": 409, "message": "fido exists"})
+	recorder = serve()
+	assert.Equal(t, 409, recorder.Code)
+
+	op.Responses.Default = nil
+	recorder = serve()
+	assert.Equal(t, 500, recorder.Code)
+	assert.JSONEq(t, `{"code":500,"message":"status code 409 is not declared for createPet"}`, recorder.Body.String())
+}
+
 func TestContextValidResponseFormat(t *testing.T) {
 	ct := "application/json"
 	spec, api := petstore.NewAPI(t)
@@ -167,10 +233,11 @@ func TestContextValidResponseFormat(t *testing.T) {
 	ctx.router = DefaultRouter(spec, ctx.api)
 
 	request, _ := http.NewRequest("GET", "http://localhost:8080", nil)
+	request = WithRequestValues(request)
 	request.Header.Set(httpkit.HeaderAccept, ct)
 
 	// check there's nothing there
-	cached, ok := context.GetOk(request, ctxResponseFormat)
+	cached, ok := ResponseFormatFrom(request.Context())
 	assert.False(t, ok)
 	assert.Empty(t, cached)
 
@@ -179,7 +246,7 @@ func TestContextValidResponseFormat(t *testing.T) {
 	assert.Equal(t, ct, mt)
 
 	// check it was cached
-	cached, ok = context.GetOk(request, ctxResponseFormat)
+	cached, ok = ResponseFormatFrom(request.Context())
 	assert.True(t, ok)
 	assert.Equal(t, ct, cached)
 
@@ -196,10 +263,11 @@ func TestContextInvalidResponseFormat(t *testing.T) {
 	ctx.router = DefaultRouter(spec, ctx.api)
 
 	request, _ := http.NewRequest("GET", "http://localhost:8080", nil)
+	request = WithRequestValues(request)
 	request.Header.Set(httpkit.HeaderAccept, ct)
 
 	// check there's nothing there
-	cached, ok := context.GetOk(request, ctxResponseFormat)
+	cached, ok := ResponseFormatFrom(request.Context())
 	assert.False(t, ok)
 	assert.Empty(t, cached)
 
@@ -208,7 +276,7 @@ func TestContextInvalidResponseFormat(t *testing.T) {
 	assert.Empty(t, mt)
 
 	// check it was cached
-	cached, ok = context.GetOk(request, ctxResponseFormat)
+	cached, ok = ResponseFormatFrom(request.Context())
 	assert.False(t, ok)
 	assert.Empty(t, cached)
 
@@ -223,9 +291,10 @@ func TestContextValidRoute(t *testing.T) {
 	ctx.router = DefaultRouter(spec, ctx.api)
 
 	request, _ := http.NewRequest("GET", "/pets", nil)
+	request = WithRequestValues(request)
 
 	// check there's nothing there
-	_, ok := context.GetOk(request, ctxMatchedRoute)
+	_, ok := MatchedRouteFrom(request.Context())
 	assert.False(t, ok)
 
 	matched, ok := ctx.RouteInfo(request)
@@ -233,7 +302,7 @@ func TestContextValidRoute(t *testing.T) {
 	assert.NotNil(t, matched)
 
 	// check it was cached
-	_, ok = context.GetOk(request, ctxMatchedRoute)
+	_, ok = MatchedRouteFrom(request.Context())
 	assert.True(t, ok)
 
 	matched, ok = ctx.RouteInfo(request)
@@ -247,9 +316,10 @@ func TestContextInvalidRoute(t *testing.T) {
 	ctx.router = DefaultRouter(spec, ctx.api)
 
 	request, _ := http.NewRequest("DELETE", "pets", nil)
+	request = WithRequestValues(request)
 
 	// check there's nothing there
-	_, ok := context.GetOk(request, ctxMatchedRoute)
+	_, ok := MatchedRouteFrom(request.Context())
 	assert.False(t, ok)
 
 	matched, ok := ctx.RouteInfo(request)
@@ -257,7 +327,7 @@ func TestContextInvalidRoute(t *testing.T) {
 	assert.Nil(t, matched)
 
 	// check it was cached
-	_, ok = context.GetOk(request, ctxMatchedRoute)
+	_, ok = MatchedRouteFrom(request.Context())
 	assert.False(t, ok)
 
 	matched, ok = ctx.RouteInfo(request)
@@ -270,10 +340,11 @@ func TestContextValidContentType(t *testing.T) {
 	ctx := NewContext(nil, nil, nil)
 
 	request, _ := http.NewRequest("GET", "http://localhost:8080", nil)
+	request = WithRequestValues(request)
 	request.Header.Set(httpkit.HeaderContentType, ct)
 
 	// check there's nothing there
-	_, ok := context.GetOk(request, ctxContentType)
+	_, _, ok := ContentTypeFrom(request.Context())
 	assert.False(t, ok)
 
 	// trigger the parse
@@ -282,7 +353,7 @@ func TestContextValidContentType(t *testing.T) {
 	assert.Equal(t, ct, mt)
 
 	// check it was cached
-	_, ok = context.GetOk(request, ctxContentType)
+	_, _, ok = ContentTypeFrom(request.Context())
 	assert.True(t, ok)
 
 	// check if the cast works and fetch from cache too
@@ -296,10 +367,11 @@ func TestContextInvalidContentType(t *testing.T) {
 	ctx := NewContext(nil, nil, nil)
 
 	request, _ := http.NewRequest("GET", "http://localhost:8080", nil)
+	request = WithRequestValues(request)
 	request.Header.Set(httpkit.HeaderContentType, ct)
 
 	// check there's nothing there
-	_, ok := context.GetOk(request, ctxContentType)
+	_, _, ok := ContentTypeFrom(request.Context())
 	assert.False(t, ok)
 
 	// trigger the parse
@@ -308,7 +380,7 @@ func TestContextInvalidContentType(t *testing.T) {
 	assert.Empty(t, mt)
 
 	// check it was not cached
-	_, ok = context.GetOk(request, ctxContentType)
+	_, _, ok = ContentTypeFrom(request.Context())
 	assert.False(t, ok)
 
 	// check if the failure continues
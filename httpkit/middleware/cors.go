@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/spec"
+)
+
+const (
+	headerOrigin                        = "Origin"
+	headerAccessControlRequestMethod    = "Access-Control-Request-Method"
+	headerAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	headerAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	headerAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	headerAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+// CORSOptions configures the cross origin resource sharing of an API,
+// the allowed methods and headers are derived from the spec
+type CORSOptions struct {
+	// AllowedOrigins are the origins that can make cross origin requests, * allows every origin
+	AllowedOrigins []string
+	// AllowCredentials allows cross origin requests with cookies and authorization headers
+	AllowCredentials bool
+	// MaxAge is the number of seconds the answer to a preflight request can be cached, zero leaves it to the client
+	MaxAge int
+}
+
+func (o *CORSOptions) allows(origin string) bool {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *CORSOptions) allowAnyOrigin() bool {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// newCORS creates a middleware that answers preflight requests and adds the CORS headers to the responses of the API
+func newCORS(ctx *Context, next http.Handler) http.Handler {
+	if ctx.cors == nil {
+		return next
+	}
+	opts := *ctx.cors
+	basePath := ctx.spec.BasePath()
+	isRoot := basePath == "" || basePath == "/"
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if !isRoot {
+			p := strings.TrimPrefix(path, basePath)
+			if len(p) == len(path) {
+				next.ServeHTTP(rw, r)
+				return
+			}
+			path = p
+		}
+		origin := r.Header.Get(headerOrigin)
+
+		// a preflight request from an origin that isn't allowed is answered like any OPTIONS request, without CORS headers
+		requested := r.Header.Get(headerAccessControlRequestMethod)
+		if r.Method == "OPTIONS" && origin != "" && requested != "" && opts.allows(origin) {
+			methods := ctx.methodsFor(path)
+			if len(methods) == 0 {
+				next.ServeHTTP(rw, r)
+				return
+			}
+			rw.Header().Set("Allow", strings.Join(methods, ","))
+			opts.allowOrigin(rw.Header(), origin)
+			rw.Header().Set(headerAccessControlAllowMethods, strings.Join(methods, ", "))
+			if route, ok := ctx.router.Lookup(requested, path); ok {
+				if headers := ctx.requestHeaders(route); len(headers) > 0 {
+					rw.Header().Set(headerAccessControlAllowHeaders, strings.Join(headers, ", "))
+				}
+			}
+			if opts.MaxAge > 0 {
+				rw.Header().Set(headerAccessControlMaxAge, strconv.Itoa(opts.MaxAge))
+			}
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if origin != "" && opts.allows(origin) {
+			opts.allowOrigin(rw.Header(), origin)
+			if route, ok := ctx.router.Lookup(r.Method, path); ok {
+				if headers := responseHeaders(route); len(headers) > 0 {
+					rw.Header().Set(headerAccessControlExposeHeaders, strings.Join(headers, ", "))
+				}
+			}
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// allowOrigin adds the headers that allow an origin, the origin is echoed unless every origin is allowed without credentials
+func (o *CORSOptions) allowOrigin(header http.Header, origin string) {
+	if o.allowAnyOrigin() && !o.AllowCredentials {
+		header.Set(headerAccessControlAllowOrigin, "*")
+		return
+	}
+	header.Set(headerAccessControlAllowOrigin, origin)
+	httpkit.AddVary(header, headerOrigin)
+	if o.AllowCredentials {
+		header.Set(headerAccessControlAllowCredentials, "true")
+	}
+}
+
+// requestHeaders returns the headers a client can send for a route: the header parameters,
+// the headers of the security schemes and the content type when the operation has a body
+func (c *Context) requestHeaders(route *MatchedRoute) []string {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(name string) {
+		name = http.CanonicalHeaderKey(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+
+	for _, param := range route.Parameters {
+		switch param.In {
+		case "header":
+			add(param.Name)
+		case "body", "formData":
+			add(httpkit.HeaderContentType)
+		}
+	}
+	for _, scheme := range c.spec.SecurityDefinitionsFor(route.Operation) {
+		switch scheme.Type {
+		case "basic", "oauth2":
+			add("Authorization")
+		case "apiKey":
+			if scheme.In == "header" {
+				add(scheme.Name)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// responseHeaders returns the headers that are declared for the responses of a route
+func responseHeaders(route *MatchedRoute) []string {
+	if route.Operation == nil || route.Operation.Responses == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var result []string
+	add := func(headers map[string]spec.Header) {
+		for name := range headers {
+			name = http.CanonicalHeaderKey(name)
+			if !seen[name] {
+				seen[name] = true
+				result = append(result, name)
+			}
+		}
+	}
+	if route.Operation.Responses.Default != nil {
+		add(route.Operation.Responses.Default.Headers)
+	}
+	for _, response := range route.Operation.Responses.StatusCodeResponses {
+		add(response.Headers)
+	}
+	sort.Strings(result)
+	return result
+}
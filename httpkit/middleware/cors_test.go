@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func corsHandler(t *testing.T, opts CORSOptions) http.Handler {
+	doc, api := petstore.NewAPI(t)
+	op := doc.Spec().Paths.Paths["/pets/{id}"].Get
+	resp := op.Responses.StatusCodeResponses[200]
+	resp.Headers = map[string]spec.Header{"X-Rate-Limit": *new(spec.Header).Typed("integer", "int32")}
+	op.Responses.StatusCodeResponses[200] = resp
+
+	context := NewContext(doc, api, nil)
+	context.SetCORS(opts)
+	return context.APIHandler()
+}
+
+func preflight(origin, method, path string) *http.Request {
+	request, _ := http.NewRequest("OPTIONS", path, nil)
+	request.Header.Set(headerOrigin, origin)
+	request.Header.Set(headerAccessControlRequestMethod, method)
+	return request
+}
+
+func TestCORSPreflight(t *testing.T) {
+	handler := corsHandler(t, CORSOptions{AllowedOrigins: []string{"http://example.com"}, MaxAge: 600})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, preflight("http://example.com", "POST", "/api/pets"))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "http://example.com", recorder.Header().Get(headerAccessControlAllowOrigin))
+	assert.Equal(t, "Origin", recorder.Header().Get("Vary"))
+	assert.Equal(t, "GET, HEAD, OPTIONS, POST", recorder.Header().Get(headerAccessControlAllowMethods))
+	assert.Equal(t, "Authorization, Content-Type", recorder.Header().Get(headerAccessControlAllowHeaders))
+	assert.Equal(t, "600", recorder.Header().Get(headerAccessControlMaxAge))
+	assert.Empty(t, recorder.Header().Get(headerAccessControlAllowCredentials))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, preflight("http://example.com", "DELETE", "/api/pets/1"))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "DELETE, GET, HEAD, OPTIONS", recorder.Header().Get(headerAccessControlAllowMethods))
+
+	// an origin that isn't allowed gets no CORS headers, so the browser blocks the request
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, preflight("http://evil.com", "POST", "/api/pets"))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(headerAccessControlAllowOrigin))
+	assert.Empty(t, recorder.Header().Get(headerAccessControlAllowMethods))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, preflight("http://example.com", "GET", "/api/unknown"))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestCORSOptionsWithoutPreflight(t *testing.T) {
+	handler := corsHandler(t, CORSOptions{AllowedOrigins: []string{"*"}})
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("OPTIONS", "/api/pets", nil)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "GET,HEAD,OPTIONS,POST", recorder.Header().Get("Allow"))
+	assert.Empty(t, recorder.Header().Get(headerAccessControlAllowOrigin))
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	handler := corsHandler(t, CORSOptions{AllowedOrigins: []string{"*"}})
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets/1", nil)
+	request.Header.Set(headerOrigin, "http://example.com")
+	request.SetBasicAuth("admin", "admin")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "*", recorder.Header().Get(headerAccessControlAllowOrigin))
+	assert.Equal(t, "X-Rate-Limit", recorder.Header().Get(headerAccessControlExposeHeaders))
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/pets/1", nil)
+	request.SetBasicAuth("admin", "admin")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(headerAccessControlAllowOrigin))
+}
+
+func TestCORSCredentials(t *testing.T) {
+	handler := corsHandler(t, CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, preflight("http://example.com", "GET", "/api/pets"))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "http://example.com", recorder.Header().Get(headerAccessControlAllowOrigin))
+	assert.Equal(t, "true", recorder.Header().Get(headerAccessControlAllowCredentials))
+	assert.Equal(t, "Origin", recorder.Header().Get("Vary"))
+}
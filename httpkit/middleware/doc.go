@@ -6,12 +6,12 @@ Pseudo middleware handler
   	"net/http"
 
   	"github.com/go-swagger/go-swagger/errors"
-  	"github.com/gorilla/context"
   )
 
   func newCompleteMiddleware(ctx *Context) http.Handler {
   	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-  		defer context.Clear(r)
+  		// the values collected for the request are carried by its context
+  		r = WithRequestValues(r)
 
   		// use context to lookup routes
   		if matched, ok := ctx.RouteInfo(r); ok {
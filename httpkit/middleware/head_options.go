@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+)
+
+// operationSummary describes an operation in the body of an answer to an OPTIONS request
+type operationSummary struct {
+	ID          string `json:"operationId,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// methodsFor returns the methods a path can be requested with, it's empty when the path isn't in the spec.
+// HEAD is added when the path has a GET operation and OPTIONS is always added.
+func (c *Context) methodsFor(path string) []string {
+	declared := c.router.OtherMethods("OPTIONS", path)
+	if len(declared) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, method := range declared {
+		seen[strings.ToUpper(method)] = true
+	}
+	if seen["GET"] {
+		seen["HEAD"] = true
+	}
+	seen["OPTIONS"] = true
+
+	var methods []string
+	for method := range seen {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// serveOptions answers an OPTIONS request with the methods of the path in the Allow header,
+// when the context describes options the operations of the path are in the body
+func (c *Context) serveOptions(rw http.ResponseWriter, r *http.Request, methods []string) {
+	rw.Header().Set("Allow", strings.Join(methods, ","))
+	if !c.describeOptions {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	operations := make(map[string]operationSummary)
+	for _, method := range methods {
+		if route, ok := c.router.Lookup(method, r.URL.Path); ok && route.Operation != nil {
+			operations[method] = operationSummary{
+				ID:          route.Operation.ID,
+				Summary:     route.Operation.Summary,
+				Description: route.Operation.Description,
+			}
+		}
+	}
+	rw.Header().Set(httpkit.HeaderContentType, httpkit.JSONMime)
+	rw.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(rw).Encode(operations); err != nil {
+		panic(err) // let the recovery middleware deal with this
+	}
+}
+
+// serveHead serves a HEAD request with the GET operation of the path, the body of the response is discarded
+// but its length is kept in the Content-Length header
+func serveHead(next http.Handler, rw http.ResponseWriter, r *http.Request) {
+	get := new(http.Request)
+	*get = *r
+	get.Method = "GET"
+
+	head := &headResponse{rw: rw}
+	next.ServeHTTP(head, get)
+	head.finish()
+}
+
+// headResponse counts the body of a response instead of sending it
+type headResponse struct {
+	rw     http.ResponseWriter
+	code   int
+	length int
+}
+
+func (h *headResponse) Header() http.Header {
+	return h.rw.Header()
+}
+
+func (h *headResponse) WriteHeader(code int) {
+	if h.code == 0 {
+		h.code = code
+	}
+}
+
+func (h *headResponse) Write(data []byte) (int, error) {
+	if h.code == 0 {
+		h.code = http.StatusOK
+	}
+	h.length += len(data)
+	return len(data), nil
+}
+
+func (h *headResponse) finish() {
+	if h.code == 0 {
+		h.code = http.StatusOK
+	}
+	if h.length > 0 && h.rw.Header().Get(headerContentLength) == "" {
+		h.rw.Header().Set(headerContentLength, strconv.Itoa(h.length))
+	}
+	h.rw.WriteHeader(h.code)
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadServedByGet(t *testing.T) {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+	handler := context.APIHandler()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	request.SetBasicAuth("admin", "admin")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	length := recorder.Body.Len()
+	assert.NotEqual(t, 0, length)
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("HEAD", "/api/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	request.SetBasicAuth("admin", "admin")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, httpkit.JSONMime, recorder.Header().Get(httpkit.HeaderContentType))
+	assert.Equal(t, strconv.Itoa(length), recorder.Header().Get(headerContentLength))
+	assert.Empty(t, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("HEAD", "/api/pets", nil)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 401, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestOptionsAllow(t *testing.T) {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+	handler := context.APIHandler()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("OPTIONS", "/api/pets", nil)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "GET,HEAD,OPTIONS,POST", recorder.Header().Get("Allow"))
+	assert.Empty(t, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("OPTIONS", "/api/pets/1", nil)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "DELETE,GET,HEAD,OPTIONS", recorder.Header().Get("Allow"))
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("OPTIONS", "/api/unknown", nil)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestOptionsDescribed(t *testing.T) {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+	context.SetDescribeOptions(true)
+	handler := context.APIHandler()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("OPTIONS", "/api/pets", nil)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "GET,HEAD,OPTIONS,POST", recorder.Header().Get("Allow"))
+	assert.Equal(t, httpkit.JSONMime, recorder.Header().Get(httpkit.HeaderContentType))
+
+	var operations map[string]operationSummary
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &operations)) {
+		assert.Len(t, operations, 2)
+		assert.Equal(t, "getAllPets", operations["GET"].ID)
+		assert.Equal(t, "Finds all pets in the system", operations["GET"].Summary)
+		assert.Equal(t, "createPet", operations["POST"].ID)
+	}
+}
@@ -0,0 +1,279 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-swagger/go-swagger/errors"
+	"github.com/go-swagger/go-swagger/spec"
+)
+
+const (
+	// XMaxBodySize is the vendor extension that declares the largest request body in bytes an operation accepts,
+	// like x-max-body-size: 1048576 or x-max-body-size: 1MB
+	XMaxBodySize = "x-max-body-size"
+	// XTimeout is the vendor extension that declares how long the handler of an operation can take, like x-timeout: 5s
+	XTimeout = "x-timeout"
+)
+
+// requestLimits are the limits of the requests for an operation, a zero value means there's no limit
+type requestLimits struct {
+	maxBodySize int64
+	timeout     time.Duration
+}
+
+// newRequestLimits creates a middleware that limits the size of the request bodies and the time the handlers take
+func newRequestLimits(ctx *Context, next http.Handler) http.Handler {
+	operations := make(map[*spec.Operation]requestLimits)
+	for _, paths := range ctx.spec.Operations() {
+		for _, op := range paths {
+			if limits, ok := operationRequestLimits(op); ok {
+				operations[op] = limits
+			}
+		}
+	}
+	if ctx.maxBodySize <= 0 && len(operations) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		route, ok := ctx.RouteInfo(r)
+		if !ok || route.Operation == nil {
+			next.ServeHTTP(rw, r)
+			return
+		}
+		limits := operations[route.Operation]
+		if limits.maxBodySize <= 0 {
+			limits.maxBodySize = ctx.maxBodySize
+		}
+
+		if limits.maxBodySize > 0 && r.Body != nil {
+			if r.ContentLength > limits.maxBodySize {
+				ctx.Respond(rw, r, route.Produces, route, bodyTooLarge(limits.maxBodySize))
+				return
+			}
+			r.Body = &limitedBody{ReadCloser: r.Body, remaining: limits.maxBodySize, max: limits.maxBodySize}
+		}
+
+		if limits.timeout > 0 {
+			serveWithTimeout(ctx, route, limits.timeout, next, rw, r)
+			return
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// operationRequestLimits reads the limits from the extensions of an operation, a limit that can't be read is skipped
+func operationRequestLimits(op *spec.Operation) (requestLimits, bool) {
+	var limits requestLimits
+	if value, ok := op.Extensions[XMaxBodySize]; ok {
+		size, err := parseByteSize(value)
+		if err != nil {
+			log.Printf("%s of %s is skipped: %v", XMaxBodySize, op.ID, err)
+		}
+		limits.maxBodySize = size
+	}
+	if value, ok := op.Extensions[XTimeout]; ok {
+		timeout, err := parseTimeout(value)
+		if err != nil {
+			log.Printf("%s of %s is skipped: %v", XTimeout, op.ID, err)
+		}
+		limits.timeout = timeout
+	}
+	return limits, limits.maxBodySize > 0 || limits.timeout > 0
+}
+
+func bodyTooLarge(max int64) error {
+	return errors.New(http.StatusRequestEntityTooLarge, "the request body is larger than %d bytes", max)
+}
+
+// limitedBody fails to read a request body that's larger than the limit
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	max       int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// one more byte tells a body of exactly the maximum size apart from a body that is too large
+		var b [1]byte
+		if n, _ := l.ReadCloser.Read(b[:]); n > 0 {
+			return 0, bodyTooLarge(l.max)
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// serveWithTimeout serves a request with a deadline, the context of the request is canceled when it passes.
+// The response is held on to until the handler is done, so the client gets a 503 from the error handler
+// of the operation when the handler takes too long.
+func serveWithTimeout(ctx *Context, route *MatchedRoute, timeout time.Duration, next http.Handler, rw http.ResponseWriter, r *http.Request) {
+	deadline, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	r = r.WithContext(deadline)
+
+	tw := &timeoutWriter{header: make(http.Header), deadline: deadline}
+	done := make(chan struct{})
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				if p != http.ErrAbortHandler {
+					// the stack of the handler is only known on this goroutine
+					p = &handlerPanic{value: p, stack: debug.Stack()}
+				}
+				panicked <- p
+			}
+		}()
+		next.ServeHTTP(tw, r)
+		close(done)
+	}()
+
+	select {
+	case p := <-panicked:
+		// the recovery middleware is on this goroutine
+		panic(p)
+	case <-done:
+		tw.lock.Lock()
+		// the handler can finish at the same time the deadline passes, the writes after it were refused
+		if deadline.Err() != nil {
+			tw.timedOut = true
+			tw.lock.Unlock()
+			break
+		}
+		defer tw.lock.Unlock()
+		for k, v := range tw.header {
+			rw.Header()[k] = v
+		}
+		if tw.code == 0 {
+			tw.code = http.StatusOK
+		}
+		rw.WriteHeader(tw.code)
+		rw.Write(tw.body.Bytes())
+		return
+	case <-deadline.Done():
+		tw.lock.Lock()
+		tw.timedOut = true
+		tw.lock.Unlock()
+	}
+	ctx.Respond(rw, r, route.Produces, route, errors.New(http.StatusServiceUnavailable, "the operation %s took longer than %s", route.Operation.ID, timeout))
+}
+
+// handlerPanic carries a panic of a handler that ran on another goroutine, with the stack of that goroutine
+type handlerPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// timeoutWriter holds on to a response until the handler is done, it stops taking writes when the deadline passed
+type timeoutWriter struct {
+	lock     sync.Mutex
+	header   http.Header
+	code     int
+	body     bytes.Buffer
+	deadline context.Context
+	timedOut bool
+}
+
+func (t *timeoutWriter) Header() http.Header {
+	return t.header
+}
+
+func (t *timeoutWriter) WriteHeader(code int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.code == 0 && !t.expired() {
+		t.code = code
+	}
+}
+
+func (t *timeoutWriter) Write(data []byte) (int, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if t.code == 0 {
+		t.code = http.StatusOK
+	}
+	return t.body.Write(data)
+}
+
+// expired tells whether the response can't be written anymore, the lock needs to be held
+func (t *timeoutWriter) expired() bool {
+	return t.timedOut || t.deadline.Err() != nil
+}
+
+// parseTimeout reads a timeout that is a duration like 500ms or a number of seconds
+func parseTimeout(value interface{}) (time.Duration, error) {
+	var timeout time.Duration
+	switch v := value.(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("%q isn't a duration", v)
+		}
+		timeout = d
+	case float64:
+		timeout = time.Duration(v * float64(time.Second))
+	default:
+		return 0, fmt.Errorf("%v isn't a duration", value)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("%v isn't positive", value)
+	}
+	return timeout, nil
+}
+
+var byteUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"B", 1},
+}
+
+// parseByteSize reads a size that is a number of bytes or a number with one of the units B, KB, MB and GB
+func parseByteSize(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case float64:
+		if v < 1 || v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v isn't a positive integer", v)
+		}
+		return int64(v), nil
+	case string:
+		s := strings.ToUpper(strings.TrimSpace(v))
+		multiplier := int64(1)
+		for _, unit := range byteUnits {
+			if strings.HasSuffix(s, unit.suffix) {
+				s, multiplier = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.multiplier
+				break
+			}
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("%q isn't a size", v)
+		}
+		return n * multiplier, nil
+	}
+	return 0, fmt.Errorf("%v isn't a size", value)
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	data := []struct {
+		value    interface{}
+		expected int64
+	}{
+		{float64(1024), 1024},
+		{"512", 512},
+		{"512B", 512},
+		{"2kb", 2048},
+		{"1 MB", 1 << 20},
+		{"3GB", 3 << 30},
+	}
+	for _, v := range data {
+		size, err := parseByteSize(v.value)
+		if assert.NoError(t, err, "value: %v", v.value) {
+			assert.Equal(t, v.expected, size, "value: %v", v.value)
+		}
+	}
+
+	for _, v := range []interface{}{float64(0), float64(1.5), "", "MB", "-1KB", "1TB", true} {
+		_, err := parseByteSize(v)
+		assert.Error(t, err, "value: %v", v)
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	timeout, err := parseTimeout("1500ms")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1500*time.Millisecond, timeout)
+	}
+	timeout, err = parseTimeout(float64(2))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2*time.Second, timeout)
+	}
+
+	for _, v := range []interface{}{"soon", "-1s", float64(0), true} {
+		_, err := parseTimeout(v)
+		assert.Error(t, err, "value: %v", v)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	context := NewContext(doc, api, nil)
+	context.SetMaxBodySize(64)
+	handler := context.APIHandler()
+
+	serve := func(body string, chunked bool) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/api/pets", strings.NewReader(body))
+		if chunked {
+			request.Body = ioutil.NopCloser(strings.NewReader(body))
+			request.ContentLength = -1
+		}
+		request.Header.Set(httpkit.HeaderContentType, httpkit.JSONMime)
+		request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+		request.SetBasicAuth("admin", "admin")
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve(largeBody, false)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	assert.JSONEq(t, `{"code":413,"message":"the request body is larger than 64 bytes"}`, recorder.Body.String())
+
+	// without a content length the body is cut off while it's consumed
+	recorder = serve(largeBody, true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+
+	recorder = serve(`{"name":"fido"}`, true)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestOperationMaxBodySize(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	op := doc.Spec().Paths.Paths["/pets"].Post
+	op.Extensions = spec.Extensions{}
+	op.Extensions.Add(XMaxBodySize, "4KB")
+	context := NewContext(doc, api, nil)
+	context.SetMaxBodySize(64)
+
+	var received string
+	mw := newRouter(context, newRequestLimits(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received = string(b)
+		rw.WriteHeader(http.StatusCreated)
+	})))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/api/pets", strings.NewReader(largeBody))
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, largeBody, received)
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("POST", "/api/pets", strings.NewReader(strings.Repeat("a", 4097)))
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
+
+func timeoutContext(t *testing.T, timeout interface{}) *Context {
+	doc, api := petstore.NewAPI(t)
+	op := doc.Spec().Paths.Paths["/pets"].Get
+	op.Extensions = spec.Extensions{}
+	op.Extensions.Add(XTimeout, timeout)
+	return NewContext(doc, api, nil)
+}
+
+func TestOperationTimeout(t *testing.T) {
+	context := timeoutContext(t, "20ms")
+	writeErr := make(chan error, 1)
+	mw := newRouter(context, newRequestLimits(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		rw.Header().Set("X-Late", "true")
+		_, err := rw.Write([]byte("too late"))
+		writeErr <- err
+	})))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.JSONEq(t, `{"code":503,"message":"the operation getAllPets took longer than 20ms"}`, recorder.Body.String())
+
+	assert.Equal(t, http.ErrHandlerTimeout, <-writeErr)
+	assert.Empty(t, recorder.Header().Get("X-Late"))
+}
+
+func TestOperationTimeoutInTime(t *testing.T) {
+	context := timeoutContext(t, float64(5))
+	mw := newRouter(context, newRequestLimits(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, ok := r.Context().Deadline()
+		assert.True(t, ok)
+		rw.Header().Set(httpkit.HeaderContentType, httpkit.JSONMime)
+		rw.WriteHeader(http.StatusAccepted)
+		rw.Write([]byte(`[]`))
+	})))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, httpkit.JSONMime, recorder.Header().Get(httpkit.HeaderContentType))
+	assert.Equal(t, `[]`, recorder.Body.String())
+}
+
+func TestOperationTimeoutPanic(t *testing.T) {
+	context := timeoutContext(t, "1s")
+	var panics []recoveredPanic
+	context.SetRecoveryHook(func(r *http.Request, recovered interface{}, stack []byte) {
+		panics = append(panics, recoveredPanic{value: recovered, stack: stack})
+	})
+	mw := newRecovery(context, newRouter(context, newRequestLimits(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	if assert.Len(t, panics, 1) {
+		assert.Equal(t, "boom", panics[0].value)
+		// the stack is the one of the handler, not the one of the goroutine that serves the request
+		assert.Contains(t, string(panics[0].stack), "TestOperationTimeoutPanic.func")
+		assert.NotContains(t, string(panics[0].stack), "serveWithTimeout(")
+	}
+}
@@ -0,0 +1,390 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the buckets of the request latency histogram
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metricsContentType is the media type of the prometheus text format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// operationKey labels the metrics of an operation
+type operationKey struct {
+	Operation string
+	Method    string
+}
+
+// responseKey labels the metrics of the responses of an operation
+type responseKey struct {
+	operationKey
+	Code int
+}
+
+type responseMetrics struct {
+	count      uint64
+	latencySum float64
+	buckets    []uint64
+	sizeSum    float64
+	sizeCount  uint64
+}
+
+// Metrics collects the requests that are served for the operations of an API,
+// it's safe to use from multiple goroutines
+type Metrics struct {
+	lock               sync.Mutex
+	buckets            []float64
+	responses          map[responseKey]*responseMetrics
+	inFlight           map[operationKey]int64
+	validationFailures map[operationKey]uint64
+	authFailures       map[operationKey]uint64
+}
+
+// NewMetrics creates a new metrics collector, without buckets the latencies are counted in the DefaultLatencyBuckets
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+
+	return &Metrics{
+		buckets:            b,
+		responses:          make(map[responseKey]*responseMetrics),
+		inFlight:           make(map[operationKey]int64),
+		validationFailures: make(map[operationKey]uint64),
+		authFailures:       make(map[operationKey]uint64),
+	}
+}
+
+func (m *Metrics) begin(key operationKey) {
+	m.lock.Lock()
+	m.inFlight[key]++
+	m.lock.Unlock()
+}
+
+func (m *Metrics) end(key operationKey, code int, latency time.Duration, size int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.inFlight[key]--
+	rk := responseKey{operationKey: key, Code: code}
+	rm, ok := m.responses[rk]
+	if !ok {
+		rm = &responseMetrics{buckets: make([]uint64, len(m.buckets))}
+		m.responses[rk] = rm
+	}
+	seconds := latency.Seconds()
+	rm.count++
+	rm.latencySum += seconds
+	for i, upper := range m.buckets {
+		if seconds <= upper {
+			rm.buckets[i]++
+		}
+	}
+	rm.sizeCount++
+	rm.sizeSum += float64(size)
+}
+
+func (m *Metrics) validationFailed(key operationKey) {
+	m.lock.Lock()
+	m.validationFailures[key]++
+	m.lock.Unlock()
+}
+
+func (m *Metrics) authFailed(key operationKey) {
+	m.lock.Lock()
+	m.authFailures[key]++
+	m.lock.Unlock()
+}
+
+// Handler returns a handler that serves the metrics in the prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", metricsContentType)
+		rw.WriteHeader(http.StatusOK)
+		if r.Method != "HEAD" {
+			m.WriteTo(rw)
+		}
+	})
+}
+
+// metricsSnapshot is a copy of the metrics, so they can be written without holding up the requests that are served
+type metricsSnapshot struct {
+	buckets            []float64
+	responses          map[responseKey]responseMetrics
+	inFlight           map[operationKey]int64
+	validationFailures map[operationKey]uint64
+	authFailures       map[operationKey]uint64
+}
+
+func (m *Metrics) snapshot() *metricsSnapshot {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	snap := &metricsSnapshot{
+		buckets:            m.buckets,
+		responses:          make(map[responseKey]responseMetrics, len(m.responses)),
+		inFlight:           make(map[operationKey]int64, len(m.inFlight)),
+		validationFailures: make(map[operationKey]uint64, len(m.validationFailures)),
+		authFailures:       make(map[operationKey]uint64, len(m.authFailures)),
+	}
+	for k, v := range m.responses {
+		rm := *v
+		rm.buckets = make([]uint64, len(v.buckets))
+		copy(rm.buckets, v.buckets)
+		snap.responses[k] = rm
+	}
+	for k, v := range m.inFlight {
+		snap.inFlight[k] = v
+	}
+	for k, v := range m.validationFailures {
+		snap.validationFailures[k] = v
+	}
+	for k, v := range m.authFailures {
+		snap.authFailures[k] = v
+	}
+	return snap
+}
+
+// WriteTo writes the metrics in the prometheus text format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	// a slow reader doesn't get to block the requests that update the metrics
+	snap := m.snapshot()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	responses := sortedResponses(snap.responses)
+
+	writeFamily(cw, "swagger_http_requests_total", "counter", "The number of requests that were served for an operation.")
+	for _, key := range responses {
+		writeSample(cw, "swagger_http_requests_total", key.labels(), float64(snap.responses[key].count))
+	}
+
+	writeFamily(cw, "swagger_http_request_duration_seconds", "histogram", "The time it took to serve the requests for an operation.")
+	for _, key := range responses {
+		rm := snap.responses[key]
+		for i, upper := range snap.buckets {
+			writeSample(cw, "swagger_http_request_duration_seconds_bucket", append(key.labels(), "le", formatFloat(upper)), float64(rm.buckets[i]))
+		}
+		writeSample(cw, "swagger_http_request_duration_seconds_bucket", append(key.labels(), "le", "+Inf"), float64(rm.count))
+		writeSample(cw, "swagger_http_request_duration_seconds_sum", key.labels(), rm.latencySum)
+		writeSample(cw, "swagger_http_request_duration_seconds_count", key.labels(), float64(rm.count))
+	}
+
+	writeFamily(cw, "swagger_http_response_size_bytes", "summary", "The size of the response bodies of an operation.")
+	for _, key := range responses {
+		rm := snap.responses[key]
+		writeSample(cw, "swagger_http_response_size_bytes_sum", key.labels(), rm.sizeSum)
+		writeSample(cw, "swagger_http_response_size_bytes_count", key.labels(), float64(rm.sizeCount))
+	}
+
+	writeFamily(cw, "swagger_http_requests_in_flight", "gauge", "The number of requests that are being served for an operation.")
+	for _, key := range sortedOperations(snap.inFlight) {
+		writeSample(cw, "swagger_http_requests_in_flight", key.labels(), float64(snap.inFlight[key]))
+	}
+
+	writeFamily(cw, "swagger_http_validation_failures_total", "counter", "The number of requests for an operation that failed validation.")
+	for _, key := range sortedCounts(snap.validationFailures) {
+		writeSample(cw, "swagger_http_validation_failures_total", key.labels(), float64(snap.validationFailures[key]))
+	}
+
+	writeFamily(cw, "swagger_http_auth_failures_total", "counter", "The number of requests for an operation that failed authentication.")
+	for _, key := range sortedCounts(snap.authFailures) {
+		writeSample(cw, "swagger_http_auth_failures_total", key.labels(), float64(snap.authFailures[key]))
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func sortedResponses(values map[responseKey]responseMetrics) []responseKey {
+	keys := make([]responseKey, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Sort(responseKeys(keys))
+	return keys
+}
+
+func sortedOperations(values map[operationKey]int64) []operationKey {
+	keys := make([]operationKey, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Sort(operationKeys(keys))
+	return keys
+}
+
+func sortedCounts(values map[operationKey]uint64) []operationKey {
+	keys := make([]operationKey, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Sort(operationKeys(keys))
+	return keys
+}
+
+func (o operationKey) labels() []string {
+	return []string{"operation", o.Operation, "method", o.Method}
+}
+
+func (o operationKey) less(other operationKey) bool {
+	if o.Operation != other.Operation {
+		return o.Operation < other.Operation
+	}
+	return o.Method < other.Method
+}
+
+func (r responseKey) labels() []string {
+	return append(r.operationKey.labels(), "code", strconv.Itoa(r.Code))
+}
+
+type operationKeys []operationKey
+
+func (o operationKeys) Len() int           { return len(o) }
+func (o operationKeys) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o operationKeys) Less(i, j int) bool { return o[i].less(o[j]) }
+
+type responseKeys []responseKey
+
+func (r responseKeys) Len() int      { return len(r) }
+func (r responseKeys) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r responseKeys) Less(i, j int) bool {
+	if r[i].operationKey != r[j].operationKey {
+		return r[i].operationKey.less(r[j].operationKey)
+	}
+	return r[i].Code < r[j].Code
+}
+
+// countingWriter keeps the first error and the number of bytes written, so the writes don't each need to be checked
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) printf(format string, args ...interface{}) {
+	if c.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(c.w, format, args...)
+	c.n += int64(n)
+	c.err = err
+}
+
+func writeFamily(w *countingWriter, name, tpe, help string) {
+	w.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, tpe)
+}
+
+// writeSample writes a sample with its labels, the labels are pairs of names and values
+func writeSample(w *countingWriter, name string, labels []string, value float64) {
+	var pairs []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+escapeLabelValue(labels[i+1])+`"`)
+	}
+	if len(pairs) > 0 {
+		w.printf("%s{%s} %s\n", name, strings.Join(pairs, ","), formatFloat(value))
+		return
+	}
+	w.printf("%s %s\n", name, formatFloat(value))
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// newMetrics creates a middleware that collects the metrics of the requests for the matched operations
+func newMetrics(ctx *Context, next http.Handler) http.Handler {
+	if ctx.metrics == nil {
+		return next
+	}
+	metrics := ctx.metrics
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		route, ok := ctx.RouteInfo(r)
+		if !ok || route.Operation == nil {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		key := operationKey{Operation: route.Operation.ID, Method: r.Method}
+		metrics.begin(key)
+		start := time.Now()
+		recorder := &statusRecorder{rw: rw}
+		completed := false
+		defer func() {
+			metrics.end(key, recorder.status(completed), time.Since(start), recorder.size)
+		}()
+		next.ServeHTTP(recorder, r)
+		completed = true
+	})
+}
+
+// statusRecorder remembers the status code and the size of a response
+type statusRecorder struct {
+	rw   http.ResponseWriter
+	code int
+	size int
+}
+
+// status returns the status code of the response, a handler that panicked before it sent a response gets a 500
+func (s *statusRecorder) status(completed bool) int {
+	switch {
+	case s.code != 0:
+		return s.code
+	case completed:
+		return http.StatusOK
+	}
+	return http.StatusInternalServerError
+}
+
+func (s *statusRecorder) Header() http.Header {
+	return s.rw.Header()
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.code == 0 {
+		s.code = code
+	}
+	s.rw.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(data []byte) (int, error) {
+	if s.code == 0 {
+		s.code = http.StatusOK
+	}
+	n, err := s.rw.Write(data)
+	s.size += n
+	return n, err
+}
+
+// countFailure counts a failed validation or authentication for the operation of a route when the context has metrics
+func (c *Context) countFailure(request *http.Request, route *MatchedRoute, auth bool) {
+	if c.metrics == nil || route == nil || route.Operation == nil {
+		return
+	}
+	key := operationKey{Operation: route.Operation.ID, Method: request.Method}
+	if auth {
+		c.metrics.authFailed(key)
+		return
+	}
+	c.metrics.validationFailed(key)
+}
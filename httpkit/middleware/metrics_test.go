@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsCollected(t *testing.T) {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+	metrics := NewMetrics(0.5, 1)
+	context.SetMetrics(metrics)
+	handler := context.APIHandler()
+
+	serve := func(method, path string, auth bool) int {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, nil)
+		request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+		if auth {
+			request.SetBasicAuth("admin", "admin")
+		}
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	assert.Equal(t, 200, serve("GET", "/api/pets", true))
+	assert.Equal(t, 200, serve("GET", "/api/pets", true))
+	assert.Equal(t, 401, serve("GET", "/api/pets", false))
+	assert.Equal(t, 422, serve("GET", "/api/pets?limit=abc", true))
+	assert.Equal(t, 404, serve("GET", "/api/unknown", true))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/metrics", nil)
+	metrics.Handler().ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, metricsContentType, recorder.Header().Get(httpkit.HeaderContentType))
+
+	body := recorder.Body.String()
+	assert.Contains(t, body, "# TYPE swagger_http_requests_total counter\n")
+	assert.Contains(t, body, `swagger_http_requests_total{operation="getAllPets",method="GET",code="200"} 2`+"\n")
+	assert.Contains(t, body, `swagger_http_requests_total{operation="getAllPets",method="GET",code="401"} 1`+"\n")
+	assert.Contains(t, body, `swagger_http_requests_total{operation="getAllPets",method="GET",code="422"} 1`+"\n")
+	assert.Contains(t, body, "# TYPE swagger_http_request_duration_seconds histogram\n")
+	assert.Contains(t, body, `swagger_http_request_duration_seconds_bucket{operation="getAllPets",method="GET",code="200",le="0.5"} 2`+"\n")
+	assert.Contains(t, body, `swagger_http_request_duration_seconds_bucket{operation="getAllPets",method="GET",code="200",le="+Inf"} 2`+"\n")
+	assert.Contains(t, body, `swagger_http_request_duration_seconds_count{operation="getAllPets",method="GET",code="200"} 2`+"\n")
+	assert.Contains(t, body, `swagger_http_response_size_bytes_count{operation="getAllPets",method="GET",code="200"} 2`+"\n")
+	assert.Contains(t, body, `swagger_http_requests_in_flight{operation="getAllPets",method="GET"} 0`+"\n")
+	assert.Contains(t, body, `swagger_http_validation_failures_total{operation="getAllPets",method="GET"} 1`+"\n")
+	assert.Contains(t, body, `swagger_http_auth_failures_total{operation="getAllPets",method="GET"} 1`+"\n")
+	// requests that don't match a route aren't collected
+	assert.NotContains(t, body, "404")
+}
+
+func TestMetricsTextFormat(t *testing.T) {
+	metrics := NewMetrics(1)
+	key := operationKey{Operation: `say "hi"\` + "\n", Method: "POST"}
+	metrics.begin(key)
+	metrics.end(key, 201, 1500*1000*1000, 12)
+	metrics.begin(key)
+
+	var buf bytes.Buffer
+	n, err := metrics.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	labels := `operation="say \"hi\"\\\n",method="POST"`
+	expected := []string{
+		`# HELP swagger_http_requests_total The number of requests that were served for an operation.`,
+		`# TYPE swagger_http_requests_total counter`,
+		`swagger_http_requests_total{` + labels + `,code="201"} 1`,
+		`# HELP swagger_http_request_duration_seconds The time it took to serve the requests for an operation.`,
+		`# TYPE swagger_http_request_duration_seconds histogram`,
+		`swagger_http_request_duration_seconds_bucket{` + labels + `,code="201",le="1"} 0`,
+		`swagger_http_request_duration_seconds_bucket{` + labels + `,code="201",le="+Inf"} 1`,
+		`swagger_http_request_duration_seconds_sum{` + labels + `,code="201"} 1.5`,
+		`swagger_http_request_duration_seconds_count{` + labels + `,code="201"} 1`,
+		`# HELP swagger_http_response_size_bytes The size of the response bodies of an operation.`,
+		`# TYPE swagger_http_response_size_bytes summary`,
+		`swagger_http_response_size_bytes_sum{` + labels + `,code="201"} 12`,
+		`swagger_http_response_size_bytes_count{` + labels + `,code="201"} 1`,
+		`# HELP swagger_http_requests_in_flight The number of requests that are being served for an operation.`,
+		`# TYPE swagger_http_requests_in_flight gauge`,
+		`swagger_http_requests_in_flight{` + labels + `} 1`,
+		`# HELP swagger_http_validation_failures_total The number of requests for an operation that failed validation.`,
+		`# TYPE swagger_http_validation_failures_total counter`,
+		`# HELP swagger_http_auth_failures_total The number of requests for an operation that failed authentication.`,
+		`# TYPE swagger_http_auth_failures_total counter`,
+	}
+	assert.Equal(t, strings.Join(expected, "\n")+"\n", buf.String())
+}
+
+// stalledWriter is a scraper that stops reading until it's released
+type stalledWriter struct {
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (s *stalledWriter) Write(p []byte) (int, error) {
+	close(s.writing)
+	<-s.release
+	return len(p), nil
+}
+
+func TestMetricsStalledScraper(t *testing.T) {
+	metrics := NewMetrics()
+	key := operationKey{Operation: "getAllPets", Method: "GET"}
+	metrics.begin(key)
+	metrics.end(key, 200, time.Millisecond, 10)
+
+	w := &stalledWriter{writing: make(chan struct{}), release: make(chan struct{})}
+	written := make(chan struct{})
+	go func() {
+		metrics.WriteTo(w)
+		close(written)
+	}()
+	<-w.writing
+
+	served := make(chan struct{})
+	go func() {
+		metrics.begin(key)
+		metrics.end(key, 200, time.Millisecond, 10)
+		close(served)
+	}()
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Error("the requests wait for the scraper")
+	}
+	close(w.release)
+	<-written
+}
@@ -22,7 +22,7 @@ const defaultMaxMemory = 32 << 20
 
 var textUnmarshalType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
 
-func newUntypedParamBinder(param spec.Parameter, spec *spec.Swagger, formats strfmt.Registry) *untypedParamBinder {
+func newUntypedParamBinder(param spec.Parameter, spec *spec.Swagger, formats strfmt.Registry, readOnly ReadOnlyPolicy) *untypedParamBinder {
 	binder := new(untypedParamBinder)
 	binder.Name = param.Name
 	binder.parameter = &param
@@ -30,7 +30,7 @@ func newUntypedParamBinder(param spec.Parameter, spec *spec.Swagger, formats str
 	if param.In != "body" {
 		binder.validator = validate.NewParamValidator(&param, formats)
 	} else {
-		binder.validator = validate.NewSchemaValidator(param.Schema, spec, param.Name, formats)
+		binder.validator = validate.NewRequestSchemaValidator(param.Schema, spec, param.Name, formats, readOnly.mode())
 	}
 
 	return binder
@@ -220,6 +220,10 @@ func (p *untypedParamBinder) Bind(request *http.Request, routeParams RouteParams
 				target.Set(reflect.ValueOf(p.parameter.Default))
 				return nil
 			}
+			// the body can fail to read with an error that has its own status, like a body that is too large
+			if e, ok := err.(errors.Error); ok {
+				return e
+			}
 			tpe := p.parameter.Type
 			if p.parameter.Format != "" {
 				tpe = p.parameter.Format
@@ -27,7 +27,7 @@ var paramFactories = []paramFactory{
 }
 
 func np(param *spec.Parameter) *untypedParamBinder {
-	return newUntypedParamBinder(*param, new(spec.Swagger), strfmt.Default)
+	return newUntypedParamBinder(*param, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 }
 
 var stringItems = new(spec.Items)
@@ -0,0 +1,435 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-swagger/go-swagger/errors"
+	"github.com/go-swagger/go-swagger/spec"
+)
+
+const (
+	// XRateLimit is the vendor extension that declares the rate limit of an operation or of the whole API,
+	// like x-rate-limit: {rate: 100, per: 1m, key: principal}
+	XRateLimit = "x-rate-limit"
+	// XConcurrencyLimit is the vendor extension that declares how many requests an operation or the whole API serves at the same time
+	XConcurrencyLimit = "x-concurrency-limit"
+
+	// HeaderRetryAfter tells a client that was limited when it can try again
+	HeaderRetryAfter = "Retry-After"
+	// HeaderRateLimitLimit is the number of requests a client can make in a burst
+	HeaderRateLimitLimit = "RateLimit-Limit"
+	// HeaderRateLimitRemaining is the number of requests a client can still make right away
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	// HeaderRateLimitReset is the number of seconds until a client can make a full burst again
+	HeaderRateLimitReset = "RateLimit-Reset"
+
+	statusTooManyRequests = 429
+)
+
+// the keys a rate limit can count the requests by, a limit without a key counts them by client IP
+const (
+	RateLimitByIP        = "ip"
+	RateLimitByPrincipal = "principal"
+	RateLimitByAPIKey    = "apiKey"
+)
+
+// RateLimit allows Rate requests Per period with bursts of up to Burst requests, the requests are counted by Key
+type RateLimit struct {
+	Rate  int
+	Per   time.Duration
+	Burst int
+	Key   string
+}
+
+// timeFor returns how long it takes to refill a number of tokens
+func (r *RateLimit) timeFor(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens * float64(r.Per) / float64(r.Rate)))
+}
+
+func (r *RateLimit) burst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Rate
+}
+
+// RateLimitResult is the state of a token bucket after a request took a token from it, or would have
+type RateLimitResult struct {
+	// Allowed is true when there was a token for the request
+	Allowed bool
+	// Remaining is the number of tokens that are left
+	Remaining int
+	// RetryAfter is the time until there's a token again, it's zero when there are tokens
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+}
+
+// RateLimitStore keeps the token buckets of the rate limits, it needs to be safe to use from multiple goroutines
+type RateLimitStore interface {
+	// Peek tells what taking a token from the bucket with this key would result in, without taking it
+	Peek(key string, limit RateLimit, now time.Time) RateLimitResult
+	// Take takes a token for a request from the bucket with this key
+	Take(key string, limit RateLimit, now time.Time) RateLimitResult
+}
+
+// RateLimitKeyFunc returns the key the requests for a route are counted by
+type RateLimitKeyFunc func(r *http.Request, route *MatchedRoute) string
+
+// RateLimitOptions configures how the rate limits and concurrency limits in the spec are enforced
+type RateLimitOptions struct {
+	// Store keeps the token buckets, when it's nil they're kept in memory
+	Store RateLimitStore
+	// Keys are the extractors for the keys a rate limit can use next to ip, principal and apiKey
+	Keys map[string]RateLimitKeyFunc
+}
+
+// tokenBucket holds the tokens of a key, the tokens are refilled when they're taken
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time
+}
+
+type memoryRateLimitStore struct {
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket
+	takes   int
+}
+
+// NewMemoryRateLimitStore creates a rate limit store that keeps the token buckets in memory
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+func (m *memoryRateLimitStore) Peek(key string, limit RateLimit, now time.Time) RateLimitResult {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.take(key, limit, now, false)
+}
+
+func (m *memoryRateLimitStore) Take(key string, limit RateLimit, now time.Time) RateLimitResult {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.take(key, limit, now, true)
+}
+
+// take refills the bucket with this key up to now and takes a token from it, the bucket only changes when the take is kept
+func (m *memoryRateLimitStore) take(key string, limit RateLimit, now time.Time, keep bool) RateLimitResult {
+	burst := float64(limit.burst())
+	perNano := float64(limit.Rate) / float64(limit.Per)
+
+	tokens, last := burst, now
+	if bucket, ok := m.buckets[key]; ok {
+		tokens, last = bucket.tokens, bucket.last
+		if elapsed := now.Sub(bucket.last); elapsed > 0 {
+			tokens, last = math.Min(burst, tokens+float64(elapsed)*perNano), now
+		}
+	}
+
+	var result RateLimitResult
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = limit.timeFor(1 - tokens)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = limit.timeFor(burst - tokens)
+	if !keep {
+		return result
+	}
+	m.buckets[key] = &tokenBucket{tokens: tokens, last: last, full: now.Add(result.Reset)}
+
+	// the buckets that filled up again are the same as new ones, so they're dropped now and then
+	m.takes++
+	if m.takes%1024 == 0 {
+		for k, b := range m.buckets {
+			if !now.Before(b.full) {
+				delete(m.buckets, k)
+			}
+		}
+	}
+	return result
+}
+
+// operationLimits are the limits of an operation or of the whole API
+type operationLimits struct {
+	scope       string
+	rate        *RateLimit
+	concurrency chan struct{}
+}
+
+type rateLimiter struct {
+	ctx        *Context
+	store      RateLimitStore
+	keys       map[string]RateLimitKeyFunc
+	global     *operationLimits
+	operations map[*spec.Operation]*operationLimits
+}
+
+// newRateLimit creates a middleware that enforces the rate limits and concurrency limits declared in the spec
+func newRateLimit(ctx *Context, next http.Handler) http.Handler {
+	if ctx.rateLimiting == nil {
+		return next
+	}
+	limiter := &rateLimiter{
+		ctx:        ctx,
+		store:      ctx.rateLimiting.Store,
+		keys:       ctx.rateLimiting.Keys,
+		operations: make(map[*spec.Operation]*operationLimits),
+	}
+	if limiter.store == nil {
+		limiter.store = NewMemoryRateLimitStore()
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(ctx.spec.Raw(), &root); err == nil {
+		limiter.global = limiter.limitsFor("*", root)
+	}
+	for _, paths := range ctx.spec.Operations() {
+		for _, op := range paths {
+			if limits := limiter.limitsFor(op.ID, op.Extensions); limits != nil {
+				limiter.operations[op] = limits
+			}
+		}
+	}
+	if limiter.global == nil && len(limiter.operations) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		route, ok := ctx.RouteInfo(r)
+		if !ok || route.Operation == nil {
+			next.ServeHTTP(rw, r)
+			return
+		}
+		var limits []*operationLimits
+		if limiter.global != nil {
+			limits = append(limits, limiter.global)
+		}
+		if l, ok := limiter.operations[route.Operation]; ok {
+			limits = append(limits, l)
+		}
+
+		var rates []*RateLimit
+		var keys []string
+		for _, l := range limits {
+			if l.rate == nil {
+				continue
+			}
+			key, err := limiter.key(l.rate.Key, r, route)
+			if err != nil {
+				ctx.Respond(rw, r, route.Produces, route, err)
+				return
+			}
+			rates, keys = append(rates, l.rate), append(keys, l.scope+"|"+key)
+		}
+
+		// a request that one of the limits rejects doesn't use up the tokens of the others
+		now := time.Now()
+		for i, rate := range rates {
+			if result := limiter.store.Peek(keys[i], *rate, now); !result.Allowed {
+				limiter.reject(rw, r, route, rate, &result)
+				return
+			}
+		}
+		var reported *RateLimitResult
+		var reportedLimit *RateLimit
+		for i, rate := range rates {
+			result := limiter.store.Take(keys[i], *rate, now)
+			if !result.Allowed {
+				// another request took the last token in the meantime
+				limiter.reject(rw, r, route, rate, &result)
+				return
+			}
+			if reported == nil || result.Remaining < reported.Remaining {
+				reported, reportedLimit = &result, rate
+			}
+		}
+		if reported != nil {
+			writeRateLimitHeaders(rw.Header(), reportedLimit, reported)
+		}
+
+		for _, l := range limits {
+			if l.concurrency == nil {
+				continue
+			}
+			select {
+			case l.concurrency <- struct{}{}:
+				defer func(c chan struct{}) { <-c }(l.concurrency)
+			default:
+				rw.Header().Set(HeaderRetryAfter, "1")
+				ctx.Respond(rw, r, route.Produces, route, errors.New(statusTooManyRequests, "there are already %d requests in progress", cap(l.concurrency)))
+				return
+			}
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// reject responds to a request that is over a rate limit
+func (l *rateLimiter) reject(rw http.ResponseWriter, r *http.Request, route *MatchedRoute, limit *RateLimit, result *RateLimitResult) {
+	writeRateLimitHeaders(rw.Header(), limit, result)
+	rw.Header().Set(HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+	l.ctx.Respond(rw, r, route.Produces, route, errors.New(statusTooManyRequests, "the rate limit of %d requests per %s was exceeded", limit.Rate, limit.Per))
+}
+
+// limitsFor reads the limits from the extensions of an operation or the spec, a limit that can't be read is skipped
+func (l *rateLimiter) limitsFor(scope string, extensions map[string]interface{}) *operationLimits {
+	limits := &operationLimits{scope: scope}
+	if value, ok := extensions[XRateLimit]; ok {
+		rate, err := parseRateLimit(value)
+		if err == nil && !l.knownKey(rate.Key) {
+			err = fmt.Errorf("unknown key %q", rate.Key)
+		}
+		if err != nil {
+			log.Printf("%s of %s is skipped: %v", XRateLimit, scope, err)
+		} else {
+			limits.rate = rate
+		}
+	}
+	if value, ok := extensions[XConcurrencyLimit]; ok {
+		if n, ok := value.(float64); ok && n >= 1 && n == math.Trunc(n) {
+			limits.concurrency = make(chan struct{}, int(n))
+		} else {
+			log.Printf("%s of %s is skipped: %v isn't a positive integer", XConcurrencyLimit, scope, value)
+		}
+	}
+	if limits.rate == nil && limits.concurrency == nil {
+		return nil
+	}
+	return limits
+}
+
+func (l *rateLimiter) knownKey(key string) bool {
+	switch key {
+	case "", RateLimitByIP, RateLimitByPrincipal, RateLimitByAPIKey:
+		return true
+	}
+	_, ok := l.keys[key]
+	return ok
+}
+
+// key returns the key the request is counted by, when the key isn't in the request the client IP is used instead.
+// A request that fails to authenticate isn't counted by principal, it gets the authentication error.
+func (l *rateLimiter) key(kind string, r *http.Request, route *MatchedRoute) (string, error) {
+	var value string
+	switch kind {
+	case "", RateLimitByIP:
+	case RateLimitByPrincipal:
+		principal, err := l.ctx.authenticate(r, route)
+		if err != nil {
+			return "", err
+		}
+		if principal != nil {
+			value = fmt.Sprint(principal)
+		}
+	case RateLimitByAPIKey:
+		for _, scheme := range l.ctx.spec.SecurityDefinitionsFor(route.Operation) {
+			if scheme.Type != "apiKey" {
+				continue
+			}
+			if scheme.In == "query" {
+				value = r.URL.Query().Get(scheme.Name)
+			} else {
+				value = r.Header.Get(scheme.Name)
+			}
+			if value != "" {
+				break
+			}
+		}
+	default:
+		value = l.keys[kind](r, route)
+	}
+	if value == "" {
+		return RateLimitByIP + ":" + clientIP(r), nil
+	}
+	return kind + ":" + value, nil
+}
+
+// clientIP returns the address of the client without its port
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func writeRateLimitHeaders(header http.Header, limit *RateLimit, result *RateLimitResult) {
+	header.Set(HeaderRateLimitLimit, strconv.Itoa(limit.burst()))
+	header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+	header.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// parseRateLimit reads a rate limit from the value of its vendor extension,
+// the period is a duration like 1m or 1h30m, or one of second, minute, hour and day
+func parseRateLimit(value interface{}) (*RateLimit, error) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v isn't an object", value)
+	}
+
+	result := new(RateLimit)
+	rate, ok := obj["rate"].(float64)
+	if !ok || rate < 1 || rate != math.Trunc(rate) {
+		return nil, fmt.Errorf("rate %v isn't a positive integer", obj["rate"])
+	}
+	result.Rate = int(rate)
+
+	switch per := obj["per"].(type) {
+	case nil:
+		result.Per = time.Second
+	case string:
+		switch strings.ToLower(per) {
+		case "second":
+			result.Per = time.Second
+		case "minute":
+			result.Per = time.Minute
+		case "hour":
+			result.Per = time.Hour
+		case "day":
+			result.Per = 24 * time.Hour
+		default:
+			d, err := time.ParseDuration(per)
+			if err != nil {
+				return nil, fmt.Errorf("per %q isn't a duration", per)
+			}
+			result.Per = d
+		}
+	default:
+		return nil, fmt.Errorf("per %v isn't a duration", per)
+	}
+	if result.Per <= 0 {
+		return nil, fmt.Errorf("per %s isn't positive", result.Per)
+	}
+
+	if b, ok := obj["burst"]; ok {
+		burst, ok := b.(float64)
+		if !ok || burst < 1 || burst != math.Trunc(burst) {
+			return nil, fmt.Errorf("burst %v isn't a positive integer", b)
+		}
+		result.Burst = int(burst)
+	}
+	if k, ok := obj["key"]; ok {
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("key %v isn't a string", k)
+		}
+		result.Key = key
+	}
+	return result, nil
+}
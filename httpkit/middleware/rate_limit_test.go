@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Rate: 2, Per: time.Second, Burst: 3}
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+
+	// peeking doesn't take a token
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, RateLimitResult{Allowed: true, Remaining: 2, Reset: 500 * time.Millisecond}, store.Peek("a", limit, now))
+	}
+	for i := 2; i >= 0; i-- {
+		result := store.Take("a", limit, now)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+	result := store.Take("a", limit, now)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, result.Reset)
+	assert.Equal(t, result, store.Peek("a", limit, now))
+
+	// the other keys have their own bucket
+	assert.True(t, store.Take("b", limit, now).Allowed)
+
+	result = store.Take("a", limit, now.Add(500*time.Millisecond))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result = store.Take("a", limit, now.Add(time.Hour))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+	assert.Equal(t, 500*time.Millisecond, result.Reset)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	rate, err := parseRateLimit(map[string]interface{}{"rate": float64(100), "per": "1m", "key": "principal"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, &RateLimit{Rate: 100, Per: time.Minute, Key: RateLimitByPrincipal}, rate)
+		assert.Equal(t, 100, rate.burst())
+	}
+
+	rate, err = parseRateLimit(map[string]interface{}{"rate": float64(5), "per": "hour", "burst": float64(10)})
+	if assert.NoError(t, err) {
+		assert.Equal(t, &RateLimit{Rate: 5, Per: time.Hour, Burst: 10}, rate)
+		assert.Equal(t, 10, rate.burst())
+	}
+
+	rate, err = parseRateLimit(map[string]interface{}{"rate": float64(5)})
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Second, rate.Per)
+	}
+
+	invalid := []interface{}{
+		"100/m",
+		map[string]interface{}{"per": "1m"},
+		map[string]interface{}{"rate": float64(1.5)},
+		map[string]interface{}{"rate": float64(0)},
+		map[string]interface{}{"rate": float64(1), "per": "fortnight"},
+		map[string]interface{}{"rate": float64(1), "per": "-1s"},
+		map[string]interface{}{"rate": float64(1), "per": float64(60)},
+		map[string]interface{}{"rate": float64(1), "burst": "many"},
+		map[string]interface{}{"rate": float64(1), "key": true},
+	}
+	for _, v := range invalid {
+		_, err := parseRateLimit(v)
+		assert.Error(t, err, "value: %v", v)
+	}
+}
+
+func TestRateLimitByPrincipal(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	op := doc.Spec().Paths.Paths["/pets"].Get
+	op.Extensions = spec.Extensions{}
+	op.Extensions.Add(XRateLimit, map[string]interface{}{"rate": float64(2), "per": "1m", "key": "principal"})
+	context := NewContext(doc, api, nil)
+	context.SetRateLimiting(RateLimitOptions{})
+	handler := context.APIHandler()
+
+	serve := func(remoteAddr string, auth bool) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/api/pets", nil)
+		request.RemoteAddr = remoteAddr
+		request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+		if auth {
+			request.SetBasicAuth("admin", "admin")
+		}
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve("10.0.0.1:1234", true)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "1", recorder.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "30", recorder.Header().Get(HeaderRateLimitReset))
+
+	// the same principal from another address shares the bucket
+	recorder = serve("10.0.0.2:1234", true)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "0", recorder.Header().Get(HeaderRateLimitRemaining))
+
+	recorder = serve("10.0.0.1:1234", true)
+	assert.Equal(t, 429, recorder.Code)
+	assert.Equal(t, "30", recorder.Header().Get(HeaderRetryAfter))
+	assert.Equal(t, "0", recorder.Header().Get(HeaderRateLimitRemaining))
+	assert.JSONEq(t, `{"code":429,"message":"the rate limit of 2 requests per 1m0s was exceeded"}`, recorder.Body.String())
+
+	// the requests without a principal get the authentication error and don't take a token
+	for i := 0; i < 3; i++ {
+		recorder = serve("10.0.0.3:1234", false)
+		assert.Equal(t, 401, recorder.Code)
+		assert.Empty(t, recorder.Header().Get(HeaderRateLimitRemaining))
+	}
+	_, api = petstore.NewAPI(t)
+	context = NewContext(doc, api, nil)
+	context.SetRateLimiting(RateLimitOptions{})
+	handler = context.APIHandler()
+	serve("10.0.0.1:1234", false)
+	recorder = serve("10.0.0.1:1234", true)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get(HeaderRateLimitRemaining))
+
+	// the operations without a limit aren't limited
+	recorder = httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets/1", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	request.SetBasicAuth("admin", "admin")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(HeaderRateLimitLimit))
+}
+
+func TestRateLimitCustomKeyAndGlobal(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	context := NewContext(doc, api, nil)
+	context.router = DefaultRouter(doc, context.api)
+	limiter := &rateLimiter{
+		ctx:   context,
+		store: NewMemoryRateLimitStore(),
+		keys: map[string]RateLimitKeyFunc{
+			"tenant": func(r *http.Request, route *MatchedRoute) string { return r.Header.Get("X-Tenant") },
+		},
+	}
+
+	global := limiter.limitsFor("*", map[string]interface{}{
+		XRateLimit:        map[string]interface{}{"rate": float64(10), "key": "tenant"},
+		XConcurrencyLimit: float64(2),
+	})
+	if assert.NotNil(t, global) {
+		assert.Equal(t, "tenant", global.rate.Key)
+		assert.Equal(t, 2, cap(global.concurrency))
+	}
+	assert.Nil(t, limiter.limitsFor("*", map[string]interface{}{XRateLimit: map[string]interface{}{"rate": float64(10), "key": "unknown"}}))
+	assert.Nil(t, limiter.limitsFor("*", map[string]interface{}{XConcurrencyLimit: "two"}))
+
+	route, _ := context.router.Lookup("GET", "/pets")
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	key := func(kind string) string {
+		k, err := limiter.key(kind, request, route)
+		assert.NoError(t, err)
+		return k
+	}
+	assert.Equal(t, "ip:10.0.0.1", key("tenant"))
+	request.Header.Set("X-Tenant", "acme")
+	assert.Equal(t, "tenant:acme", key("tenant"))
+	assert.Equal(t, "ip:10.0.0.1", key(""))
+	assert.Equal(t, "ip:10.0.0.1", key(RateLimitByAPIKey))
+}
+
+func TestRateLimitRejectedKeepsTokens(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	var root map[string]interface{}
+	assert.NoError(t, json.Unmarshal(doc.Raw(), &root))
+	root[XRateLimit] = map[string]interface{}{"rate": float64(3), "per": "1m"}
+	raw, _ := json.Marshal(root)
+	doc, err := spec.New(raw, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	op := doc.Spec().Paths.Paths["/pets"].Get
+	op.Extensions = spec.Extensions{}
+	op.Extensions.Add(XRateLimit, map[string]interface{}{"rate": float64(1), "per": "1m"})
+	context := NewContext(doc, api, nil)
+	context.SetRateLimiting(RateLimitOptions{})
+	handler := context.APIHandler()
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", path, nil)
+		request.RemoteAddr = "10.0.0.1:1234"
+		request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+		request.SetBasicAuth("admin", "admin")
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	assert.Equal(t, 200, serve("/api/pets").Code)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, 429, serve("/api/pets").Code)
+	}
+	// the requests the operation limit rejected didn't use up the global limit
+	recorder := serve("/api/pets/1")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get(HeaderRateLimitRemaining))
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	op := doc.Spec().Paths.Paths["/pets"].Get
+	op.Extensions = spec.Extensions{}
+	op.Extensions.Add(XConcurrencyLimit, float64(1))
+	context := NewContext(doc, api, nil)
+	context.SetRateLimiting(RateLimitOptions{})
+
+	started, release := make(chan struct{}), make(chan struct{})
+	mw := newRouter(context, newRateLimit(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		rw.WriteHeader(http.StatusOK)
+	})))
+
+	done := make(chan int)
+	go func() {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/api/pets", nil)
+		mw.ServeHTTP(recorder, request)
+		done <- recorder.Code
+	}()
+	<-started
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 429, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get(HeaderRetryAfter))
+
+	close(release)
+	assert.Equal(t, 200, <-done)
+}
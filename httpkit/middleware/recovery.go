@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-swagger/go-swagger/errors"
+)
+
+// RecoveryHook gets the value and the stack of a panic that was recovered while a request was served
+type RecoveryHook func(r *http.Request, recovered interface{}, stack []byte)
+
+// logPanic is the recovery hook of a context without one, it writes the panic to the standard logger
+func logPanic(r *http.Request, recovered interface{}, stack []byte) {
+	log.Printf("panic serving %s %s: %v\n%s", r.Method, r.URL.Path, recovered, stack)
+}
+
+// newRecovery creates a middleware that recovers from a panic in the next handler.
+// When the response wasn't started yet the client gets a 500 from the error handler of the operation,
+// otherwise the connection is aborted so the client doesn't take a partial response for a complete one.
+func newRecovery(ctx *Context, next http.Handler) http.Handler {
+	hook := ctx.recoveryHook
+	if hook == nil {
+		hook = logPanic
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		r = WithRequestValues(r)
+		tracker := &headerTracker{rw: rw}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			stack := debug.Stack()
+			if hp, ok := recovered.(*handlerPanic); ok {
+				recovered, stack = hp.value, hp.stack
+			}
+			hook(r, recovered, stack)
+
+			if tracker.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			// the headers that describe the body that wasn't sent don't fit the error
+			rw.Header().Del(headerContentLength)
+			rw.Header().Del(headerContentEncoding)
+			route, _ := MatchedRouteFrom(r.Context())
+			produces := ctx.spec.RequiredProduces()
+			if route != nil {
+				produces = route.Produces
+			}
+			ctx.Respond(rw, r, produces, route, errors.New(http.StatusInternalServerError, "internal server error"))
+		}()
+		next.ServeHTTP(tracker, r)
+	})
+}
+
+// headerTracker remembers whether the headers of a response were sent
+type headerTracker struct {
+	rw          http.ResponseWriter
+	wroteHeader bool
+}
+
+func (h *headerTracker) Header() http.Header {
+	return h.rw.Header()
+}
+
+func (h *headerTracker) WriteHeader(code int) {
+	h.wroteHeader = true
+	h.rw.WriteHeader(code)
+}
+
+func (h *headerTracker) Write(data []byte) (int, error) {
+	h.wroteHeader = true
+	return h.rw.Write(data)
+}
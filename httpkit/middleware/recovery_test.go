@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/stretchr/testify/assert"
+)
+
+type recoveredPanic struct {
+	value interface{}
+	stack []byte
+}
+
+func recoveryContext(t *testing.T, panics *[]recoveredPanic) *Context {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+	context.SetRecoveryHook(func(r *http.Request, recovered interface{}, stack []byte) {
+		*panics = append(*panics, recoveredPanic{value: recovered, stack: stack})
+	})
+	return context
+}
+
+func TestRecoveryBeforeResponse(t *testing.T) {
+	var panics []recoveredPanic
+	context := recoveryContext(t, &panics)
+	mw := newRecovery(context, newRouter(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set(headerContentLength, "100")
+		panic("boom")
+	})))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, httpkit.JSONMime, recorder.Header().Get(httpkit.HeaderContentType))
+	assert.Empty(t, recorder.Header().Get(headerContentLength))
+	assert.JSONEq(t, `{"code":500,"message":"internal server error"}`, recorder.Body.String())
+
+	if assert.Len(t, panics, 1) {
+		assert.Equal(t, "boom", panics[0].value)
+		assert.Contains(t, string(panics[0].stack), "recovery_test.go")
+	}
+}
+
+func TestRecoveryAfterResponse(t *testing.T) {
+	var panics []recoveredPanic
+	context := recoveryContext(t, &panics)
+	mw := newRecovery(context, newRouter(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`[{"id":1`))
+		panic("boom")
+	})))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	var recovered interface{}
+	assert.Panics(t, func() {
+		defer func() {
+			if recovered = recover(); recovered != nil {
+				panic(recovered)
+			}
+		}()
+		mw.ServeHTTP(recorder, request)
+	})
+	assert.Equal(t, http.ErrAbortHandler, recovered)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `[{"id":1`, recorder.Body.String())
+	assert.Len(t, panics, 1)
+}
+
+func TestRecoveryProducerPanic(t *testing.T) {
+	var panics []recoveredPanic
+	spec, api := petstore.NewAPI(t)
+	api.RegisterProducer(httpkit.JSONMime, httpkit.ProducerFunc(func(w io.Writer, data interface{}) error {
+		return stderrors.New("can't produce")
+	}))
+	context := NewContext(spec, api, nil)
+	context.SetRecoveryHook(func(r *http.Request, recovered interface{}, stack []byte) {
+		panics = append(panics, recoveredPanic{value: recovered, stack: stack})
+	})
+	metrics := NewMetrics()
+	context.SetMetrics(metrics)
+	context.SetCompression(CompressionOptions{})
+	handler := context.APIHandler()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	request.Header.Set(headerAcceptEncoding, "gzip")
+	request.SetBasicAuth("admin", "admin")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(headerContentEncoding))
+	assert.JSONEq(t, `{"code":500,"message":"internal server error"}`, recorder.Body.String())
+	if assert.Len(t, panics, 1) {
+		assert.EqualError(t, panics[0].value.(error), "can't produce")
+	}
+
+	var buf bytes.Buffer
+	metrics.WriteTo(&buf)
+	assert.Contains(t, buf.String(), `swagger_http_requests_total{operation="getAllPets",method="GET",code="500"} 1`)
+}
@@ -19,10 +19,10 @@ type untypedRequestBinder struct {
 }
 
 // NewRequestBinder creates a new binder for reading a request.
-func newUntypedRequestBinder(parameters map[string]spec.Parameter, spec *spec.Swagger, formats strfmt.Registry) *untypedRequestBinder {
+func newUntypedRequestBinder(parameters map[string]spec.Parameter, spec *spec.Swagger, formats strfmt.Registry, readOnly ReadOnlyPolicy) *untypedRequestBinder {
 	binders := make(map[string]*untypedParamBinder)
 	for fieldName, param := range parameters {
-		binders[fieldName] = newUntypedParamBinder(param, spec, formats)
+		binders[fieldName] = newUntypedParamBinder(param, spec, formats, readOnly)
 	}
 	return &untypedRequestBinder{
 		Parameters:   parameters,
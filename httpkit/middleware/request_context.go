@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// requestValues holds the values that are collected while a request is served,
+// they're carried by the context of the request so every handler that gets the request sees the same values.
+// A handler can serve a request on another goroutine, like when it has a timeout, so the values are guarded by a lock.
+type requestValues struct {
+	lock   sync.RWMutex
+	values map[contextKey]interface{}
+}
+
+// WithRequestValues returns a request with room for the values the API collects while serving it,
+// like the matched route and the security principal. The API handler does this before it routes a request,
+// a request without room for the values is still served but nothing gets cached for it.
+func WithRequestValues(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(ctxRequestValues).(*requestValues); ok {
+		return r
+	}
+	values := &requestValues{values: make(map[contextKey]interface{})}
+	return r.WithContext(context.WithValue(r.Context(), ctxRequestValues, values))
+}
+
+func getRequestValue(ctx context.Context, key contextKey) (interface{}, bool) {
+	values, ok := ctx.Value(ctxRequestValues).(*requestValues)
+	if !ok {
+		return nil, false
+	}
+	values.lock.RLock()
+	defer values.lock.RUnlock()
+	v, ok := values.values[key]
+	return v, ok
+}
+
+func setRequestValue(r *http.Request, key contextKey, value interface{}) {
+	if values, ok := r.Context().Value(ctxRequestValues).(*requestValues); ok {
+		values.lock.Lock()
+		values.values[key] = value
+		values.lock.Unlock()
+	}
+}
+
+// MatchedRouteFrom returns the route that was matched for the request with this context
+func MatchedRouteFrom(ctx context.Context) (*MatchedRoute, bool) {
+	v, ok := getRequestValue(ctx, ctxMatchedRoute)
+	if !ok {
+		return nil, false
+	}
+	route, ok := v.(*MatchedRoute)
+	return route, ok
+}
+
+// SecurityPrincipalFrom returns the principal that was authenticated for the request with this context
+func SecurityPrincipalFrom(ctx context.Context) (interface{}, bool) {
+	return getRequestValue(ctx, ctxSecurityPrincipal)
+}
+
+// BoundParamsFrom returns the parameters that were bound for the request with this context,
+// they're only returned when they passed validation
+func BoundParamsFrom(ctx context.Context) (interface{}, bool) {
+	v, ok := getRequestValue(ctx, ctxBoundParams)
+	if !ok {
+		return nil, false
+	}
+	val, ok := v.(*validation)
+	if !ok || len(val.result) > 0 {
+		return nil, false
+	}
+	return val.bound, true
+}
+
+// ResponseFormatFrom returns the media type that was negotiated for the response of the request with this context
+func ResponseFormatFrom(ctx context.Context) (string, bool) {
+	v, ok := getRequestValue(ctx, ctxResponseFormat)
+	if !ok {
+		return "", false
+	}
+	format, ok := v.(string)
+	return format, ok
+}
+
+// ContentTypeFrom returns the media type and charset of the body of the request with this context
+func ContentTypeFrom(ctx context.Context) (string, string, bool) {
+	v, ok := getRequestValue(ctx, ctxContentType)
+	if !ok {
+		return "", "", false
+	}
+	val, ok := v.(*contentTypeValue)
+	if !ok {
+		return "", "", false
+	}
+	return val.MediaType, val.Charset, true
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestValuesInHandlers(t *testing.T) {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+
+	var route *MatchedRoute
+	var principal interface{}
+	var hasRoute, hasPrincipal bool
+	mw := newRouter(context, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		matched, _ := context.RouteInfo(r)
+		context.Authorize(r, matched)
+		route, hasRoute = MatchedRouteFrom(r.Context())
+		principal, hasPrincipal = SecurityPrincipalFrom(r.Context())
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/api/pets", nil)
+	request.SetBasicAuth("admin", "admin")
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, hasRoute)
+	if assert.NotNil(t, route) {
+		assert.Equal(t, "getAllPets", route.Operation.ID)
+	}
+	assert.True(t, hasPrincipal)
+	assert.Equal(t, "admin", principal)
+
+	// the values belong to the request that was served, the original request has none
+	_, ok := MatchedRouteFrom(request.Context())
+	assert.False(t, ok)
+}
+
+func TestRequestWithoutValues(t *testing.T) {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+	context.router = DefaultRouter(spec, context.api)
+
+	request, _ := http.NewRequest("GET", "/pets", nil)
+	matched, ok := context.RouteInfo(request)
+	assert.True(t, ok)
+	assert.NotNil(t, matched)
+
+	_, ok = MatchedRouteFrom(request.Context())
+	assert.False(t, ok)
+
+	values := WithRequestValues(request)
+	assert.True(t, values != request)
+	assert.True(t, values == WithRequestValues(values))
+}
@@ -198,7 +198,7 @@ func TestRequestBindingDefaultValue(t *testing.T) {
 
 	req, _ := http.NewRequest("POST", uri.String(), bytes.NewBuffer(nil))
 	req.Header.Set("Content-Type", "application/json")
-	binder := newUntypedRequestBinder(op3, new(spec.Swagger), strfmt.Default)
+	binder := newUntypedRequestBinder(op3, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 
 	data := make(map[string]interface{})
 	err := binder.Bind(req, RouteParams(nil), httpkit.JSONConsumer(), &data)
@@ -217,20 +217,65 @@ func TestRequestBindingDefaultValue(t *testing.T) {
 	assert.Equal(t, "hello", string(data["picture"].(strfmt.Base64)))
 }
 
+func parametersForReadOnly() map[string]spec.Parameter {
+	idSchema := spec.StringProperty()
+	idSchema.ReadOnly = true
+	petSchema := new(spec.Schema).Typed("object", "").WithRequired("id", "name")
+	petSchema.SetProperty("id", *idSchema)
+	petSchema.SetProperty("name", *spec.StringProperty())
+	return map[string]spec.Parameter{"Pet": *spec.BodyParam("pet", petSchema)}
+}
+
+func TestRequestBindingReadOnly(t *testing.T) {
+	bind := func(policy ReadOnlyPolicy, body string) (map[string]interface{}, error) {
+		binder := newUntypedRequestBinder(parametersForReadOnly(), new(spec.Swagger), strfmt.Default, policy)
+		req, _ := http.NewRequest("POST", "http://localhost:8002/pets", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		data := make(map[string]interface{})
+		err := binder.Bind(req, nil, httpkit.JSONConsumer(), &data)
+		pet, _ := data["pet"].(map[string]interface{})
+		return pet, err
+	}
+
+	for _, policy := range []ReadOnlyPolicy{AllowReadOnly, RejectReadOnly, StripReadOnly} {
+		pet, err := bind(policy, `{"name":"toby"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "toby", pet["name"])
+
+		_, err = bind(policy, `{}`)
+		assert.Error(t, err)
+	}
+
+	pet, err := bind(AllowReadOnly, `{"id":"1","name":"toby"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", pet["id"])
+
+	_, err = bind(RejectReadOnly, `{"id":"1","name":"toby"}`)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "pet.id in body is read only")
+	}
+
+	pet, err = bind(StripReadOnly, `{"id":"1","name":"toby"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "toby", pet["name"])
+	_, ok := pet["id"]
+	assert.False(t, ok)
+}
+
 func TestRequestBindingForInvalid(t *testing.T) {
 
 	invalidParam := spec.QueryParam("some")
 
 	op1 := map[string]spec.Parameter{"Some": *invalidParam}
 
-	binder := newUntypedRequestBinder(op1, new(spec.Swagger), strfmt.Default)
+	binder := newUntypedRequestBinder(op1, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 	req, _ := http.NewRequest("GET", "http://localhost:8002/hello?name=the-name", nil)
 
 	err := binder.Bind(req, nil, new(stubConsumer), new(jsonRequestParams))
 	assert.Error(t, err)
 
 	op2 := parametersForJSONRequestParams("")
-	binder = newUntypedRequestBinder(op2, new(spec.Swagger), strfmt.Default)
+	binder = newUntypedRequestBinder(op2, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 
 	req, _ = http.NewRequest("POST", "http://localhost:8002/hello/1?name=the-name", bytes.NewBuffer([]byte(`{"name":"toby","age":32}`)))
 	req.Header.Set("Content-Type", "application(")
@@ -246,7 +291,7 @@ func TestRequestBindingForInvalid(t *testing.T) {
 
 	invalidMultiParam := spec.HeaderParam("tags").CollectionOf(new(spec.Items), "multi")
 	op3 := map[string]spec.Parameter{"Tags": *invalidMultiParam}
-	binder = newUntypedRequestBinder(op3, new(spec.Swagger), strfmt.Default)
+	binder = newUntypedRequestBinder(op3, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 
 	req, _ = http.NewRequest("POST", "http://localhost:8002/hello/1?name=the-name", bytes.NewBuffer([]byte(`{}`)))
 	req.Header.Set("Content-Type", "application/json")
@@ -257,7 +302,7 @@ func TestRequestBindingForInvalid(t *testing.T) {
 	invalidMultiParam = spec.PathParam("").CollectionOf(new(spec.Items), "multi")
 
 	op4 := map[string]spec.Parameter{"Tags": *invalidMultiParam}
-	binder = newUntypedRequestBinder(op4, new(spec.Swagger), strfmt.Default)
+	binder = newUntypedRequestBinder(op4, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 
 	req, _ = http.NewRequest("POST", "http://localhost:8002/hello/1?name=the-name", bytes.NewBuffer([]byte(`{}`)))
 	req.Header.Set("Content-Type", "application/json")
@@ -268,7 +313,7 @@ func TestRequestBindingForInvalid(t *testing.T) {
 	invalidInParam := spec.HeaderParam("tags").Typed("string", "")
 	invalidInParam.In = "invalid"
 	op5 := map[string]spec.Parameter{"Tags": *invalidInParam}
-	binder = newUntypedRequestBinder(op5, new(spec.Swagger), strfmt.Default)
+	binder = newUntypedRequestBinder(op5, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 
 	req, _ = http.NewRequest("POST", "http://localhost:8002/hello/1?name=the-name", bytes.NewBuffer([]byte(`{}`)))
 	req.Header.Set("Content-Type", "application/json")
@@ -282,7 +327,7 @@ func TestRequestBindingForValid(t *testing.T) {
 	for _, fmt := range []string{"csv", "pipes", "tsv", "ssv", "multi"} {
 		op1 := parametersForJSONRequestParams(fmt)
 
-		binder := newUntypedRequestBinder(op1, new(spec.Swagger), strfmt.Default)
+		binder := newUntypedRequestBinder(op1, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 
 		lval := []string{"one", "two", "three"}
 		queryString := ""
@@ -321,7 +366,7 @@ func TestRequestBindingForValid(t *testing.T) {
 
 	op1 := parametersForJSONRequestParams("")
 
-	binder := newUntypedRequestBinder(op1, new(spec.Swagger), strfmt.Default)
+	binder := newUntypedRequestBinder(op1, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 	urlStr := "http://localhost:8002/hello/1?name=the-name&tags=one,two,three"
 	req, _ := http.NewRequest("POST", urlStr, bytes.NewBuffer([]byte(`{"name":"toby","age":32}`)))
 	req.Header.Set("Content-Type", "application/json;charset=utf-8")
@@ -345,7 +390,7 @@ func TestRequestBindingForValid(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json;charset=utf-8")
 	req.Header.Set("X-Request-Id", "1325959595")
 	op2 := parametersForJSONRequestSliceParams("")
-	binder = newUntypedRequestBinder(op2, new(spec.Swagger), strfmt.Default)
+	binder = newUntypedRequestBinder(op2, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 	data3 := jsonRequestSlice{}
 	err = binder.Bind(req, []RouteParam{{"id", "1"}}, httpkit.JSONConsumer(), &data3)
 
@@ -373,7 +418,7 @@ func parametersForFormUpload() map[string]spec.Parameter {
 
 func TestFormUpload(t *testing.T) {
 	params := parametersForFormUpload()
-	binder := newUntypedRequestBinder(params, new(spec.Swagger), strfmt.Default)
+	binder := newUntypedRequestBinder(params, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 
 	urlStr := "http://localhost:8002/hello"
 	req, _ := http.NewRequest("POST", urlStr, bytes.NewBufferString(`name=the-name&age=32`))
@@ -403,7 +448,7 @@ func paramsForFileUpload() *untypedRequestBinder {
 	fileParam := spec.FileParam("file")
 
 	params := map[string]spec.Parameter{"Name": *nameParam, "File": *fileParam}
-	return newUntypedRequestBinder(params, new(spec.Swagger), strfmt.Default)
+	return newUntypedRequestBinder(params, new(spec.Swagger), strfmt.Default, AllowReadOnly)
 }
 
 func TestBindingFileUpload(t *testing.T) {
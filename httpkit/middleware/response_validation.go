@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-swagger/go-swagger/errors"
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/validate"
+	"github.com/go-swagger/go-swagger/spec"
+)
+
+// ResponseViolationsHeader is the header that lists the violations of a response when they are reported
+const ResponseViolationsHeader = "X-Response-Violations"
+
+// ResponseValidationMode determines what happens when a response doesn't match the responses of its operation.
+// Responses are validated against the spec for development and contract testing, this buffers every response.
+type ResponseValidationMode int
+
+const (
+	// SkipResponseValidation sends the responses without validating them
+	SkipResponseValidation ResponseValidationMode = iota
+	// LogResponseViolations logs the violations and sends the response unchanged
+	LogResponseViolations
+	// ReportResponseViolations logs the violations and adds them to the response in the X-Response-Violations header
+	ReportResponseViolations
+	// FailResponseViolations logs the violations and replaces the response with an internal server error
+	FailResponseViolations
+)
+
+// newResponseValidation creates a middleware that validates the responses of the next handler
+func newResponseValidation(ctx *Context, next http.Handler) http.Handler {
+	if ctx.responseValidation == SkipResponseValidation {
+		return next
+	}
+
+	// the references in the response schemas are resolved against the raw document, it has the definitions as json
+	var root interface{}
+	if err := json.Unmarshal(ctx.spec.Raw(), &root); err != nil {
+		panic(err)
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		route, ok := ctx.RouteInfo(r)
+		if !ok || route.Operation == nil {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		buf := newBufferedResponse()
+		next.ServeHTTP(buf, r)
+		if buf.code == 0 {
+			buf.code = http.StatusOK
+		}
+
+		violations := ctx.validateResponse(r, route, buf, root)
+		if len(violations) > 0 {
+			msgs := make([]string, len(violations))
+			for i, v := range violations {
+				msgs[i] = v.Error()
+			}
+			log.Printf("%s %s: the response doesn't match the spec: %s", r.Method, r.URL.Path, strings.Join(msgs, "; "))
+
+			switch ctx.responseValidation {
+			case ReportResponseViolations:
+				for _, msg := range msgs {
+					buf.header.Add(ResponseViolationsHeader, msg)
+				}
+			case FailResponseViolations:
+				ctx.Respond(rw, r, route.Produces, route, errors.New(http.StatusInternalServerError, "the response doesn't match the spec: %s", strings.Join(msgs, "; ")))
+				return
+			}
+		}
+		buf.flush(rw)
+	})
+}
+
+// validateResponse checks the status code, headers and body of a response against the responses of the operation
+func (c *Context) validateResponse(r *http.Request, route *MatchedRoute, buf *bufferedResponse, root interface{}) []error {
+	if route.Operation.Responses == nil {
+		return nil
+	}
+
+	response, ok := c.responseFor(route.Operation.Responses, buf.code)
+	if !ok {
+		return []error{fmt.Errorf("status code %d is not declared for %s", buf.code, route.Operation.ID)}
+	}
+
+	var result []error
+	var names []string
+	for name := range response.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := c.validateResponseHeader(name, response.Headers[name], buf.header); err != nil {
+			result = append(result, err)
+		}
+	}
+
+	// some responses are sent without a body even when they have a schema
+	if response.Schema == nil || buf.body.Len() == 0 || r.Method == "HEAD" {
+		return result
+	}
+	mt, _, err := mime.ParseMediaType(buf.header.Get(httpkit.HeaderContentType))
+	if err != nil {
+		return append(result, fmt.Errorf("the content type %q of the response can't be parsed", buf.header.Get(httpkit.HeaderContentType)))
+	}
+	consumer, ok := c.api.ConsumersFor([]string{mt})[mt]
+	if !ok {
+		// without a consumer the body can't be read back, so it can't be validated either
+		return result
+	}
+	var data interface{}
+	if err := consumer.Consume(bytes.NewReader(buf.body.Bytes()), &data); err != nil {
+		return append(result, fmt.Errorf("the response body can't be read as %s: %v", mt, err))
+	}
+	// the validator expands the references of the schema in place, so it gets a copy
+	schema := *response.Schema
+	res := validate.NewSchemaValidator(&schema, root, "body", c.api.Formats()).Validate(data)
+	if res != nil && res.HasErrors() {
+		result = append(result, res.Errors...)
+	}
+	return result
+}
+
+// responseFor finds the declared response for a status code, falling back to the default response
+func (c *Context) responseFor(responses *spec.Responses, code int) (spec.Response, bool) {
+	response, ok := responses.StatusCodeResponses[code]
+	if !ok {
+		if responses.Default == nil {
+			return response, false
+		}
+		response = *responses.Default
+	}
+	if ref := response.Ref.GetURL(); ref != nil {
+		if rr, ok := c.spec.Spec().Responses[filepath.Base(ref.Fragment)]; ok {
+			return rr, true
+		}
+	}
+	return response, true
+}
+
+// validateResponseHeader checks a declared header is present in the response and that its value is valid
+func (c *Context) validateResponseHeader(name string, header spec.Header, actual http.Header) error {
+	if actual.Get(name) == "" {
+		return fmt.Errorf("the response header %s is missing", name)
+	}
+
+	// a header has the same validations as a header parameter, so it's read and validated like one
+	b, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	param := spec.HeaderParam(name)
+	if err := json.Unmarshal(b, param); err != nil {
+		return err
+	}
+	param.Name, param.In = name, "header"
+
+	binder := newUntypedParamBinder(*param, c.spec.Spec(), c.api.Formats(), c.readOnly)
+	tpe := binder.Type()
+	if tpe == nil {
+		return nil
+	}
+	target := reflect.Indirect(reflect.New(tpe))
+	if err := binder.Bind(&http.Request{Header: actual}, nil, nil, target); err != nil {
+		return err
+	}
+	if res := binder.validator.Validate(target.Interface()); res != nil && res.HasErrors() {
+		return res.AsError()
+	}
+	return nil
+}
+
+// bufferedResponse captures a response so it can be validated before it's sent
+type bufferedResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header)}
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.code == 0 {
+		b.code = code
+	}
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	if b.code == 0 {
+		b.code = http.StatusOK
+	}
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponse) flush(rw http.ResponseWriter) {
+	for k, v := range b.header {
+		rw.Header()[k] = v
+	}
+	rw.WriteHeader(b.code)
+	if b.body.Len() > 0 {
+		rw.Write(b.body.Bytes())
+	}
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/internal/testing/petstore"
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func responder(code int, body string, headers map[string]string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set(httpkit.HeaderContentType, httpkit.JSONMime)
+		for k, v := range headers {
+			rw.Header().Set(k, v)
+		}
+		rw.WriteHeader(code)
+		rw.Write([]byte(body))
+	})
+}
+
+func responseValidationContext(t *testing.T, mode ResponseValidationMode) *Context {
+	spec, api := petstore.NewAPI(t)
+	context := NewContext(spec, api, nil)
+	context.SetResponseValidation(mode)
+	context.router = DefaultRouter(spec, context.api)
+	return context
+}
+
+func TestResponseValidationSkipped(t *testing.T) {
+	context := responseValidationContext(t, SkipResponseValidation)
+	mw := newResponseValidation(context, responder(200, `{"id":200,"name":"Dog"}`, nil))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(ResponseViolationsHeader))
+	assert.Equal(t, `{"id":200,"name":"Dog"}`, recorder.Body.String())
+}
+
+func TestResponseValidationValid(t *testing.T) {
+	context := responseValidationContext(t, FailResponseViolations)
+	mw := newResponseValidation(context, responder(200, `{"id":1,"name":"Dog"}`, nil))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, httpkit.JSONMime, recorder.Header().Get(httpkit.HeaderContentType))
+	assert.Equal(t, `{"id":1,"name":"Dog"}`, recorder.Body.String())
+
+	mw = newResponseValidation(context, responder(200, `[{"id":1,"name":"Dog"},{"id":2,"name":"Cat"}]`, nil))
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/pets", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+
+	// the default response covers the other status codes
+	mw = newResponseValidation(context, responder(404, `{"code":404,"message":"not found"}`, nil))
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 404, recorder.Code)
+}
+
+func TestResponseValidationReport(t *testing.T) {
+	context := responseValidationContext(t, ReportResponseViolations)
+	mw := newResponseValidation(context, responder(200, `{"id":200,"name":"Dog"}`, nil))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get(ResponseViolationsHeader))
+	assert.Equal(t, `{"id":200,"name":"Dog"}`, recorder.Body.String())
+
+	mw = newResponseValidation(context, responder(200, `{"id":`, nil))
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Header().Get(ResponseViolationsHeader), "can't be read")
+}
+
+func TestResponseValidationFail(t *testing.T) {
+	context := responseValidationContext(t, FailResponseViolations)
+	mw := newResponseValidation(context, responder(200, `{"id":200,"name":"Dog"}`, nil))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "the response doesn't match the spec")
+}
+
+func TestResponseValidationStatusCode(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	doc.Spec().Paths.Paths["/pets/{id}"].Get.Responses.Default = nil
+	context := NewContext(doc, api, nil)
+	context.SetResponseValidation(ReportResponseViolations)
+	context.router = DefaultRouter(doc, context.api)
+	mw := newResponseValidation(context, responder(404, `{"code":404,"message":"not found"}`, nil))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, 404, recorder.Code)
+	assert.Equal(t, "status code 404 is not declared for getPetById", recorder.Header().Get(ResponseViolationsHeader))
+}
+
+func TestResponseValidationHeaders(t *testing.T) {
+	doc, api := petstore.NewAPI(t)
+	limit := new(spec.Header).Typed("integer", "int32")
+	limit.Maximum = new(float64)
+	*limit.Maximum = 10
+	op := doc.Spec().Paths.Paths["/pets/{id}"].Get
+	resp := op.Responses.StatusCodeResponses[200]
+	resp.Headers = map[string]spec.Header{"X-Rate-Limit": *limit}
+	op.Responses.StatusCodeResponses[200] = resp
+
+	context := NewContext(doc, api, nil)
+	context.SetResponseValidation(ReportResponseViolations)
+	context.router = DefaultRouter(doc, context.api)
+
+	mw := newResponseValidation(context, responder(200, `{"id":1,"name":"Dog"}`, map[string]string{"X-Rate-Limit": "5"}))
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Empty(t, recorder.Header().Get(ResponseViolationsHeader))
+	assert.Equal(t, "5", recorder.Header().Get("X-Rate-Limit"))
+
+	mw = newResponseValidation(context, responder(200, `{"id":1,"name":"Dog"}`, nil))
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.Equal(t, "the response header X-Rate-Limit is missing", recorder.Header().Get(ResponseViolationsHeader))
+
+	mw = newResponseValidation(context, responder(200, `{"id":1,"name":"Dog"}`, map[string]string{"X-Rate-Limit": "50"}))
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/pets/1", nil)
+	mw.ServeHTTP(recorder, request)
+	assert.NotEmpty(t, recorder.Header().Get(ResponseViolationsHeader))
+}
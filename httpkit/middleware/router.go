@@ -9,7 +9,6 @@ import (
 	"github.com/go-swagger/go-swagger/httpkit"
 	"github.com/go-swagger/go-swagger/spec"
 	"github.com/go-swagger/go-swagger/strfmt"
-	"github.com/gorilla/context"
 	"github.com/naoina/denco"
 )
 
@@ -36,12 +35,16 @@ func (r RouteParams) Get(name string) string {
 
 func newRouter(ctx *Context, next http.Handler) http.Handler {
 	if ctx.router == nil {
-		ctx.router = DefaultRouter(ctx.spec, ctx.api)
+		factory := ctx.routerFactory
+		if factory == nil {
+			factory = DefaultRouterFactory
+		}
+		ctx.router = factory(ctx.spec, ctx.api, ctx.readOnly)
 	}
 	isRoot := ctx.spec.BasePath() == "" || ctx.spec.BasePath() == "/"
 
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-		defer context.Clear(r)
+		r = WithRequestValues(r)
 		// use context to lookup routes
 		if isRoot {
 			if _, ok := ctx.RouteInfo(r); ok {
@@ -57,6 +60,20 @@ func newRouter(ctx *Context, next http.Handler) http.Handler {
 				}
 			}
 		}
+		// a HEAD request is served by the GET operation of the path and an OPTIONS request lists the methods of the path
+		if r.Method == "HEAD" {
+			if _, ok := ctx.router.Lookup("GET", r.URL.Path); ok {
+				serveHead(next, rw, r)
+				return
+			}
+		}
+		if r.Method == "OPTIONS" {
+			if methods := ctx.methodsFor(r.URL.Path); len(methods) > 0 {
+				ctx.serveOptions(rw, r, methods)
+				return
+			}
+		}
+
 		// Not found, check if it exists in the other methods first
 		if others := ctx.AllowedMethods(r); len(others) > 0 {
 			ctx.Respond(rw, r, ctx.spec.RequiredProduces(), nil, errors.MethodNotAllowed(r.Method, others))
@@ -86,10 +103,24 @@ type Router interface {
 	OtherMethods(method, path string) []string
 }
 
+// RouterFactory creates a router for the operations of a spec,
+// the read only policy determines how the router binds request bodies
+type RouterFactory func(*spec.Document, RoutableAPI, ReadOnlyPolicy) Router
+
+var (
+	// DefaultRouterFactory creates the default router, it matches the routes with denco
+	DefaultRouterFactory RouterFactory = newDefaultRouter
+	// GorillaRouterFactory creates a router that matches the routes with gorilla/mux
+	GorillaRouterFactory RouterFactory = newGorillaRouter
+	// StandardRouterFactory creates a router that only uses the standard library
+	StandardRouterFactory RouterFactory = newStandardRouter
+)
+
 type defaultRouteBuilder struct {
-	spec    *spec.Document
-	api     RoutableAPI
-	records map[string][]denco.Record
+	spec     *spec.Document
+	api      RoutableAPI
+	readOnly ReadOnlyPolicy
+	records  map[string][]denco.Record
 }
 
 type defaultRouter struct {
@@ -98,17 +129,22 @@ type defaultRouter struct {
 	routers map[string]*denco.Router
 }
 
-func newDefaultRouteBuilder(spec *spec.Document, api RoutableAPI) *defaultRouteBuilder {
+func newDefaultRouteBuilder(spec *spec.Document, api RoutableAPI, readOnly ReadOnlyPolicy) *defaultRouteBuilder {
 	return &defaultRouteBuilder{
-		spec:    spec,
-		api:     api,
-		records: make(map[string][]denco.Record),
+		spec:     spec,
+		api:      api,
+		readOnly: readOnly,
+		records:  make(map[string][]denco.Record),
 	}
 }
 
 // DefaultRouter creates a default implemenation of the router
 func DefaultRouter(spec *spec.Document, api RoutableAPI) Router {
-	builder := newDefaultRouteBuilder(spec, api)
+	return newDefaultRouter(spec, api, AllowReadOnly)
+}
+
+func newDefaultRouter(spec *spec.Document, api RoutableAPI, readOnly ReadOnlyPolicy) Router {
+	builder := newDefaultRouteBuilder(spec, api, readOnly)
 	if spec != nil {
 		for method, paths := range spec.Operations() {
 			for path, operation := range paths {
@@ -144,7 +180,7 @@ type MatchedRoute struct {
 
 func (d *defaultRouter) Lookup(method, path string) (*MatchedRoute, bool) {
 	if router, ok := d.routers[strings.ToUpper(method)]; ok {
-		if m, rp, ok := router.Lookup(path); ok && m != nil {
+		if m, rp, ok := router.Lookup(path); ok && m != nil && !hasEmptyParam(rp) {
 			if entry, ok := m.(*routeEntry); ok {
 				var params RouteParams
 				for _, p := range rp {
@@ -162,7 +198,7 @@ func (d *defaultRouter) OtherMethods(method, path string) []string {
 	var methods []string
 	for k, v := range d.routers {
 		if k != mn {
-			if _, _, ok := v.Lookup(path); ok {
+			if _, rp, ok := v.Lookup(path); ok && !hasEmptyParam(rp) {
 				methods = append(methods, k)
 				continue
 			}
@@ -171,31 +207,62 @@ func (d *defaultRouter) OtherMethods(method, path string) []string {
 	return methods
 }
 
+// hasEmptyParam is true when denco matched a parameter with an empty path segment,
+// a path parameter is always required so those paths don't match the route
+func hasEmptyParam(params denco.Params) bool {
+	for _, p := range params {
+		if p.Value == "" {
+			return true
+		}
+	}
+	return false
+}
+
 var pathConverter = regexp.MustCompile(`{(\w+)}`)
 
 func (d *defaultRouteBuilder) AddRoute(method, path string, operation *spec.Operation) {
-	mn := strings.ToUpper(method)
+	if entry, ok := newRouteEntry(d.spec, d.api, d.readOnly, method, path, operation); ok {
+		mn := strings.ToUpper(method)
+		d.records[mn] = append(d.records[mn], denco.NewRecord(pathConverter.ReplaceAllString(path, ":$1"), entry))
+	}
+}
+
+// newRouteEntry creates the entry a router returns for the route of an operation,
+// there is no route for an operation when the API has no handler for it
+func newRouteEntry(spec *spec.Document, api RoutableAPI, readOnly ReadOnlyPolicy, method, path string, operation *spec.Operation) (*routeEntry, bool) {
+	handler, ok := api.HandlerFor(operation.ID)
+	if !ok {
+		return nil, false
+	}
+
+	consumes := spec.ConsumesFor(operation)
+	produces := spec.ProducesFor(operation)
+	parameters := spec.ParamsFor(method, path)
+	definitions := spec.SecurityDefinitionsFor(operation)
+
+	return &routeEntry{
+		PathPattern:    path,
+		BasePath:       spec.BasePath(),
+		Operation:      operation,
+		Handler:        handler,
+		Consumes:       consumes,
+		Produces:       produces,
+		Consumers:      api.ConsumersFor(consumes),
+		Producers:      api.ProducersFor(produces),
+		Parameters:     parameters,
+		Formats:        api.Formats(),
+		Binder:         newUntypedRequestBinder(parameters, spec.Spec(), api.Formats(), readOnly),
+		Authenticators: api.AuthenticatorsFor(definitions),
+	}, true
+}
 
-	if handler, ok := d.api.HandlerFor(operation.ID); ok {
-		consumes := d.spec.ConsumesFor(operation)
-		produces := d.spec.ProducesFor(operation)
-		parameters := d.spec.ParamsFor(method, path)
-		definitions := d.spec.SecurityDefinitionsFor(operation)
-
-		record := denco.NewRecord(pathConverter.ReplaceAllString(path, ":$1"), &routeEntry{
-			Operation:      operation,
-			Handler:        handler,
-			Consumes:       consumes,
-			Produces:       produces,
-			Consumers:      d.api.ConsumersFor(consumes),
-			Producers:      d.api.ProducersFor(produces),
-			Parameters:     parameters,
-			Formats:        d.api.Formats(),
-			Binder:         newUntypedRequestBinder(parameters, d.spec.Spec(), d.api.Formats()),
-			Authenticators: d.api.AuthenticatorsFor(definitions),
-		})
-		d.records[mn] = append(d.records[mn], record)
+// pathParamNames returns the names of the parameters in a path template in the order they appear in
+func pathParamNames(path string) []string {
+	var names []string
+	for _, m := range pathConverter.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
 	}
+	return names
 }
 
 func (d *defaultRouteBuilder) Build() *defaultRouter {
@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/go-swagger/go-swagger/httpkit"
+	"github.com/go-swagger/go-swagger/httpkit/middleware/untyped"
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+// every router has to pass the tests in this suite
+var conformingRouters = map[string]RouterFactory{
+	"denco":    DefaultRouterFactory,
+	"gorilla":  GorillaRouterFactory,
+	"standard": StandardRouterFactory,
+}
+
+const conformanceSpec = `{
+  "swagger": "2.0",
+  "info": {"title": "routes", "version": "1.0"},
+  "basePath": "/api",
+  "produces": ["application/json"],
+  "paths": {
+    "/": {"get": {"operationId": "root", "responses": {"200": {"description": "ok"}}}},
+    "/pets": {
+      "get": {"operationId": "listPets", "responses": {"200": {"description": "ok"}}},
+      "post": {"operationId": "createPet", "responses": {"200": {"description": "ok"}}}
+    },
+    "/pets/mine": {"get": {"operationId": "myPets", "responses": {"200": {"description": "ok"}}}},
+    "/pets/{id}": {
+      "get": {"operationId": "getPet", "responses": {"200": {"description": "ok"}}},
+      "delete": {"operationId": "deletePet", "responses": {"204": {"description": "ok"}}},
+      "parameters": [{"name": "id", "in": "path", "required": true, "type": "string"}]
+    },
+    "/pets/{id}/tags/{tag}": {
+      "get": {"operationId": "getPetTag", "responses": {"200": {"description": "ok"}}},
+      "parameters": [
+        {"name": "id", "in": "path", "required": true, "type": "string"},
+        {"name": "tag", "in": "path", "required": true, "type": "string"}
+      ]
+    },
+    "/stores/{store}/pets/{id}": {
+      "get": {"operationId": "getStorePet", "responses": {"200": {"description": "ok"}}},
+      "parameters": [
+        {"name": "store", "in": "path", "required": true, "type": "string"},
+        {"name": "id", "in": "path", "required": true, "type": "string"}
+      ]
+    },
+    "/files/{name}": {
+      "get": {"operationId": "getFile", "responses": {"200": {"description": "ok"}}},
+      "parameters": [{"name": "name", "in": "path", "required": true, "type": "string"}]
+    },
+    "/unhandled": {"get": {"operationId": "unhandled", "responses": {"200": {"description": "ok"}}}}
+  }
+}`
+
+type routeConformance struct {
+	Method      string
+	Path        string
+	OperationID string
+	Params      RouteParams
+}
+
+var routeConformanceCases = []routeConformance{
+	{"GET", "/", "root", nil},
+	{"GET", "/pets", "listPets", nil},
+	{"get", "/pets", "listPets", nil},
+	{"POST", "/pets", "createPet", nil},
+	{"GET", "/pets/mine", "myPets", nil},
+	{"GET", "/pets/12", "getPet", RouteParams{{"id", "12"}}},
+	{"DELETE", "/pets/12", "deletePet", RouteParams{{"id", "12"}}},
+	{"GET", "/pets/mine2", "getPet", RouteParams{{"id", "mine2"}}},
+	{"GET", "/pets/12/tags/red", "getPetTag", RouteParams{{"id", "12"}, {"tag", "red"}}},
+	{"GET", "/stores/main/pets/12", "getStorePet", RouteParams{{"store", "main"}, {"id", "12"}}},
+	{"GET", "/files/report.pdf", "getFile", RouteParams{{"name", "report.pdf"}}},
+	{"GET", "/files/a b", "getFile", RouteParams{{"name", "a b"}}},
+	{"GET", "/files/-", "getFile", RouteParams{{"name", "-"}}},
+	{"GET", "/pets/", "", nil},
+	{"GET", "/pets/12/", "", nil},
+	{"GET", "/pets//tags/red", "", nil},
+	{"GET", "/pets/12/tags", "", nil},
+	{"GET", "/pets/12/tags/red/blue", "", nil},
+	{"GET", "/files/a/b", "", nil},
+	{"GET", "/nopets", "", nil},
+	{"GET", "/unhandled", "", nil},
+	{"PUT", "/pets", "", nil},
+}
+
+type otherMethodsConformance struct {
+	Method  string
+	Path    string
+	Methods []string
+}
+
+var otherMethodsConformanceCases = []otherMethodsConformance{
+	{"PUT", "/pets", []string{"GET", "POST"}},
+	{"GET", "/pets", []string{"POST"}},
+	{"GET", "/pets/12", []string{"DELETE"}},
+	{"POST", "/pets/mine", []string{"DELETE", "GET"}},
+	{"POST", "/pets/12/tags/red", []string{"GET"}},
+	{"GET", "/pets/12/", nil},
+	{"DELETE", "/nopets", nil},
+}
+
+func conformanceAPI(t *testing.T) (*spec.Document, *untyped.API) {
+	doc, err := spec.New([]byte(conformanceSpec), "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	api := untyped.NewAPI(doc)
+	api.RegisterConsumer(httpkit.JSONMime, httpkit.JSONConsumer())
+	api.RegisterProducer(httpkit.JSONMime, httpkit.JSONProducer())
+	for _, id := range []string{"root", "listPets", "createPet", "myPets", "getPet", "deletePet", "getPetTag", "getStorePet", "getFile"} {
+		api.RegisterOperation(id, httpkit.OperationHandlerFunc(func(params interface{}) (interface{}, error) {
+			return params, nil
+		}))
+	}
+	return doc, api
+}
+
+func TestRouterConformance(t *testing.T) {
+	for name, factory := range conformingRouters {
+		doc, api := conformanceAPI(t)
+		router := factory(doc, newRoutableUntypedAPI(doc, api, new(Context)), AllowReadOnly)
+
+		for _, tc := range routeConformanceCases {
+			route, ok := router.Lookup(tc.Method, tc.Path)
+			if tc.OperationID == "" {
+				assert.False(t, ok, "%s: %s %s should not match", name, tc.Method, tc.Path)
+				continue
+			}
+			if assert.True(t, ok, "%s: %s %s should match", name, tc.Method, tc.Path) {
+				assert.Equal(t, tc.OperationID, route.Operation.ID, "%s: %s %s", name, tc.Method, tc.Path)
+				assert.Equal(t, tc.Params, route.Params, "%s: %s %s", name, tc.Method, tc.Path)
+				assert.NotNil(t, route.Handler, "%s: %s %s", name, tc.Method, tc.Path)
+				assert.NotNil(t, route.Binder, "%s: %s %s", name, tc.Method, tc.Path)
+			}
+		}
+
+		for _, tc := range otherMethodsConformanceCases {
+			methods := router.OtherMethods(tc.Method, tc.Path)
+			sort.Strings(methods)
+			assert.Equal(t, tc.Methods, methods, "%s: other methods for %s %s", name, tc.Method, tc.Path)
+		}
+	}
+}
+
+func TestRouterFactory(t *testing.T) {
+	for name, factory := range conformingRouters {
+		doc, api := conformanceAPI(t)
+		context := NewContext(doc, api, nil)
+		context.SetRouterFactory(factory)
+		handler := context.APIHandler()
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/api/pets/12/tags/red", nil)
+		request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+		handler.ServeHTTP(recorder, request)
+		assert.Equal(t, 200, recorder.Code, name)
+		assert.Equal(t, "{\"id\":\"12\",\"tag\":\"red\"}\n", recorder.Body.String(), name)
+
+		recorder = httptest.NewRecorder()
+		request, _ = http.NewRequest("PUT", "/api/pets/12", nil)
+		request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+		handler.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code, name)
+	}
+}
+
+func TestContextMount(t *testing.T) {
+	doc, api := conformanceAPI(t)
+	context := NewContext(doc, api, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+	context.Mount(mux)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/health", nil)
+	mux.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/stores/main/pets/12", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	mux.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "{\"id\":\"12\",\"store\":\"main\"}\n", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/pets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	mux.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/api/nopets", nil)
+	request.Header.Set(httpkit.HeaderAccept, httpkit.JSONMime)
+	mux.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
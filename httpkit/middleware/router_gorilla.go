@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-swagger/go-swagger/spec"
+	"github.com/gorilla/mux"
+)
+
+type gorillaRouter struct {
+	routers map[string]*mux.Router
+	entries map[*mux.Route]*routeEntry
+}
+
+// GorillaRouter creates a router that matches the routes of a spec with gorilla/mux
+func GorillaRouter(spec *spec.Document, api RoutableAPI) Router {
+	return newGorillaRouter(spec, api, AllowReadOnly)
+}
+
+func newGorillaRouter(spec *spec.Document, api RoutableAPI, readOnly ReadOnlyPolicy) Router {
+	router := &gorillaRouter{
+		routers: make(map[string]*mux.Router),
+		entries: make(map[*mux.Route]*routeEntry),
+	}
+	if spec == nil {
+		return router
+	}
+	// gorilla/mux tries the routes in the order they are added, so they're added in the order of precedence of the standard router
+	routes := make(map[string]standardRoutes)
+	for method, paths := range spec.Operations() {
+		for path, operation := range paths {
+			if entry, ok := newRouteEntry(spec, api, readOnly, method, path, operation); ok {
+				mn := strings.ToUpper(method)
+				routes[mn] = append(routes[mn], newStandardRoute(path, entry))
+			}
+		}
+	}
+	for method, rts := range routes {
+		sort.Sort(rts)
+		// every method has its own router, so the routes only need to match the path
+		router.routers[method] = mux.NewRouter()
+		for _, rt := range rts {
+			router.entries[router.routers[method].NewRoute().Path(rt.path)] = rt.entry
+		}
+	}
+	return router
+}
+
+func (g *gorillaRouter) match(method, path string) (*mux.RouteMatch, bool) {
+	router, ok := g.routers[strings.ToUpper(method)]
+	if !ok {
+		return nil, false
+	}
+	var match mux.RouteMatch
+	if !router.Match(&http.Request{Method: strings.ToUpper(method), URL: &url.URL{Path: path}}, &match) || match.Route == nil {
+		return nil, false
+	}
+	return &match, true
+}
+
+func (g *gorillaRouter) Lookup(method, path string) (*MatchedRoute, bool) {
+	match, ok := g.match(method, path)
+	if !ok {
+		return nil, false
+	}
+	entry, ok := g.entries[match.Route]
+	if !ok {
+		return nil, false
+	}
+	var params RouteParams
+	for _, name := range pathParamNames(entry.PathPattern) {
+		params = append(params, RouteParam{Name: name, Value: match.Vars[name]})
+	}
+	return &MatchedRoute{routeEntry: *entry, Params: params}, true
+}
+
+func (g *gorillaRouter) OtherMethods(method, path string) []string {
+	mn := strings.ToUpper(method)
+	var methods []string
+	for k := range g.routers {
+		if k != mn {
+			if _, ok := g.match(k, path); ok {
+				methods = append(methods, k)
+			}
+		}
+	}
+	return methods
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-swagger/go-swagger/spec"
+)
+
+type standardRoute struct {
+	path    string
+	pattern *regexp.Regexp
+	names   []string
+	rank    []int
+	entry   *routeEntry
+}
+
+type standardRoutes []*standardRoute
+
+func (s standardRoutes) Len() int      { return len(s) }
+func (s standardRoutes) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// Less puts the routes with static segments before the routes with parameters in the same place,
+// so /pets/mine is matched before /pets/{id}
+func (s standardRoutes) Less(i, j int) bool {
+	left, right := s[i].rank, s[j].rank
+	for k := 0; k < len(left) && k < len(right); k++ {
+		if left[k] != right[k] {
+			return left[k] < right[k]
+		}
+	}
+	if len(left) != len(right) {
+		return len(left) < len(right)
+	}
+	return s[i].path < s[j].path
+}
+
+type standardRouter struct {
+	routes map[string]standardRoutes
+}
+
+// StandardRouter creates a router that only uses the standard library, it matches the path templates of a spec itself.
+// A path parameter matches a single path segment.
+func StandardRouter(spec *spec.Document, api RoutableAPI) Router {
+	return newStandardRouter(spec, api, AllowReadOnly)
+}
+
+func newStandardRouter(spec *spec.Document, api RoutableAPI, readOnly ReadOnlyPolicy) Router {
+	router := &standardRouter{routes: make(map[string]standardRoutes)}
+	if spec == nil {
+		return router
+	}
+	for method, paths := range spec.Operations() {
+		for path, operation := range paths {
+			entry, ok := newRouteEntry(spec, api, readOnly, method, path, operation)
+			if !ok {
+				continue
+			}
+			mn := strings.ToUpper(method)
+			router.routes[mn] = append(router.routes[mn], newStandardRoute(path, entry))
+		}
+	}
+	for _, routes := range router.routes {
+		sort.Sort(routes)
+	}
+	return router
+}
+
+func newStandardRoute(path string, entry *routeEntry) *standardRoute {
+	route := &standardRoute{path: path, entry: entry, names: pathParamNames(path)}
+
+	var rx []string
+	for _, segment := range strings.Split(path, "/") {
+		rank := 0
+		if pathConverter.MatchString(segment) {
+			rank = 1
+		}
+		route.rank = append(route.rank, rank)
+
+		var parts []string
+		for _, part := range pathConverter.Split(segment, -1) {
+			parts = append(parts, regexp.QuoteMeta(part))
+		}
+		rx = append(rx, strings.Join(parts, "([^/]+)"))
+	}
+	route.pattern = regexp.MustCompile("^" + strings.Join(rx, "/") + "$")
+	return route
+}
+
+func (s *standardRouter) match(method, path string) (*standardRoute, []string, bool) {
+	for _, route := range s.routes[strings.ToUpper(method)] {
+		if values := route.pattern.FindStringSubmatch(path); values != nil {
+			return route, values[1:], true
+		}
+	}
+	return nil, nil, false
+}
+
+func (s *standardRouter) Lookup(method, path string) (*MatchedRoute, bool) {
+	route, values, ok := s.match(method, path)
+	if !ok {
+		return nil, false
+	}
+	var params RouteParams
+	for i, name := range route.names {
+		params = append(params, RouteParam{Name: name, Value: values[i]})
+	}
+	return &MatchedRoute{routeEntry: *route.entry, Params: params}, true
+}
+
+func (s *standardRouter) OtherMethods(method, path string) []string {
+	mn := strings.ToUpper(method)
+	var methods []string
+	for k := range s.routes {
+		if k != mn {
+			if _, _, ok := s.match(k, path); ok {
+				methods = append(methods, k)
+			}
+		}
+	}
+	return methods
+}
@@ -126,7 +126,7 @@ func TestRouterStruct(t *testing.T) {
 }
 
 func petAPIRouterBuilder(spec *spec.Document, api *untyped.API) *defaultRouteBuilder {
-	builder := newDefaultRouteBuilder(spec, newRoutableUntypedAPI(spec, api, new(Context)))
+	builder := newDefaultRouteBuilder(spec, newRoutableUntypedAPI(spec, api, new(Context)), AllowReadOnly)
 	builder.AddRoute("GET", "/pets", spec.AllPaths()["/pets"].Get)
 	builder.AddRoute("POST", "/pets", spec.AllPaths()["/pets"].Post)
 	builder.AddRoute("DELETE", "/pets/{id}", spec.AllPaths()["/pets/{id}"].Delete)
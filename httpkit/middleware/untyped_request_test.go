@@ -18,7 +18,7 @@ import (
 
 func TestUntypedFormPost(t *testing.T) {
 	params := parametersForFormUpload()
-	binder := newUntypedRequestBinder(params, nil, strfmt.Default)
+	binder := newUntypedRequestBinder(params, nil, strfmt.Default, AllowReadOnly)
 
 	urlStr := "http://localhost:8002/hello"
 	req, _ := http.NewRequest("POST", urlStr, bytes.NewBufferString(`name=the-name&age=32`))
@@ -100,7 +100,7 @@ func TestUntypedFileUpload(t *testing.T) {
 func TestUntypedBindingTypesForValid(t *testing.T) {
 
 	op2 := parametersForAllTypes("")
-	binder := newUntypedRequestBinder(op2, nil, strfmt.Default)
+	binder := newUntypedRequestBinder(op2, nil, strfmt.Default, AllowReadOnly)
 
 	confirmed := true
 	name := "thomas"
@@ -1,14 +1,37 @@
 package middleware
 
 import (
-	"mime"
 	"net/http"
 
 	"github.com/go-swagger/go-swagger/errors"
 	"github.com/go-swagger/go-swagger/httpkit"
-	"github.com/go-swagger/go-swagger/swag"
+	"github.com/go-swagger/go-swagger/internal/validate"
 )
 
+// ReadOnlyPolicy determines what happens with properties marked as read only when they are sent in a request body.
+// Read only properties are never required in a request body.
+type ReadOnlyPolicy int
+
+const (
+	// AllowReadOnly accepts read only properties in a request body and validates them like any other property
+	AllowReadOnly ReadOnlyPolicy = iota
+	// RejectReadOnly fails the validation of a request body that contains read only properties
+	RejectReadOnly
+	// StripReadOnly removes the read only properties from a request body before it's handed to the handler
+	StripReadOnly
+)
+
+func (p ReadOnlyPolicy) mode() validate.ReadOnlyMode {
+	switch p {
+	case RejectReadOnly:
+		return validate.ReadOnlyRejected
+	case StripReadOnly:
+		return validate.ReadOnlyStripped
+	default:
+		return validate.ReadOnlyOptional
+	}
+}
+
 // NewValidation starts a new validation middleware
 func newValidation(ctx *Context, next http.Handler) http.Handler {
 
@@ -42,16 +65,23 @@ func (ub untypedBinder) BindRequest(r *http.Request, route *MatchedRoute, consum
 	return nil
 }
 
-// ContentType validates the content type of a request
-func validateContentType(allowed []string, actual string) *errors.Validation {
-	mt, _, err := mime.ParseMediaType(actual)
-	if err != nil {
-		return errors.InvalidContentType(actual, allowed)
+// validateContentType validates the content type of a request, it returns the allowed media type that matches it.
+// The media type parameters, like the charset, have to agree with the allowed media type when both have them
+// and a structured syntax suffix matches the media type it stands for.
+func validateContentType(allowed []string, actual string) (string, *errors.Validation) {
+	if mt, ok := httpkit.MatchContentType(allowed, actual); ok {
+		return mt, nil
 	}
-	if swag.ContainsStringsCI(allowed, mt) {
-		return nil
+	return "", errors.InvalidContentType(actual, allowed)
+}
+
+// requestContentType returns the content type header of a request with its parameters,
+// a request without a content type has the default media type
+func requestContentType(request *http.Request) string {
+	if ct := request.Header.Get(httpkit.HeaderContentType); ct != "" {
+		return ct
 	}
-	return errors.InvalidContentType(actual, allowed)
+	return httpkit.DefaultMime
 }
 
 func validateRequest(ctx *Context, request *http.Request, route *MatchedRoute) *validation {
@@ -85,11 +115,11 @@ func (v *validation) parameters() {
 
 func (v *validation) contentType() {
 	if httpkit.CanHaveBody(v.request.Method) {
-		ct, _, err := v.context.ContentType(v.request)
-		if err != nil {
+		if _, _, err := v.context.ContentType(v.request); err != nil {
 			v.result = append(v.result, err)
 		} else {
-			if err := validateContentType(v.route.Consumes, ct); err != nil {
+			ct, err := validateContentType(v.route.Consumes, requestContentType(v.request))
+			if err != nil {
 				v.result = append(v.result, err)
 			}
 			v.route.Consumer = v.route.Consumers[ct]
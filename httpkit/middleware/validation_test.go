@@ -79,10 +79,20 @@ func TestValidateContentType(t *testing.T) {
 		{"text/html;           charset=utf-8", []string{"application/json"}, errors.InvalidContentType("text/html;           charset=utf-8", []string{"application/json"})},
 		{"application(", []string{"application/json"}, errors.InvalidContentType("application(", []string{"application/json"})},
 		{"application/json;char*", []string{"application/json"}, errors.InvalidContentType("application/json;char*", []string{"application/json"})},
+		{"Application/JSON", []string{"application/json"}, nil},
+		{"application/vnd.api+json", []string{"application/json"}, nil},
+		{"application/json", []string{"application/vnd.api+json"}, nil},
+		{"application/vnd.api+json", []string{"application/vnd.other+json"}, errors.InvalidContentType("application/vnd.api+json", []string{"application/vnd.other+json"})},
+		{"text/plain", []string{"text/*"}, nil},
+		{"text/plain", []string{"*/*"}, nil},
+		{"text/plain; charset=UTF-8", []string{"text/plain; charset=utf-8"}, nil},
+		{"text/plain; charset=latin1", []string{"text/plain; charset=utf-8"}, errors.InvalidContentType("text/plain; charset=latin1", []string{"text/plain; charset=utf-8"})},
+		{"application/json; version=2", []string{"application/json; version=1"}, errors.InvalidContentType("application/json; version=2", []string{"application/json; version=1"})},
+		{"*/*", []string{"application/json"}, errors.InvalidContentType("*/*", []string{"application/json"})},
 	}
 
 	for _, v := range data {
-		err := validateContentType(v.allowed, v.hdr)
+		_, err := validateContentType(v.allowed, v.hdr)
 		if v.err == nil {
 			assert.NoError(t, err, "input: %q", v.hdr)
 		} else {
@@ -0,0 +1,176 @@
+package httpkit
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// the kinds of matches between a media range and a media type, a more specific match wins
+const (
+	noMatch = iota
+	anyMatch
+	typeMatch
+	suffixMatch
+	exactMatch
+)
+
+type mediaType struct {
+	Type    string
+	Subtype string
+	Params  map[string]string
+	Q       float64
+}
+
+// parseMediaType parses a media type or media range, the q parameter is taken out of the other parameters
+func parseMediaType(value string) (mediaType, bool) {
+	mt, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+	if err != nil {
+		return mediaType{}, false
+	}
+	// mime accepts a media type without a subtype, but */* is the only range without one
+	if mt == "*" {
+		mt = "*/*"
+	}
+	parts := strings.SplitN(mt, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return mediaType{}, false
+	}
+
+	result := mediaType{Type: parts[0], Subtype: parts[1], Params: make(map[string]string), Q: 1}
+	for k, v := range params {
+		if k == "q" {
+			q, err := strconv.ParseFloat(v, 64)
+			if err != nil || q < 0 || q > 1 {
+				return mediaType{}, false
+			}
+			result.Q = q
+			continue
+		}
+		if k == charsetKey {
+			v = strings.ToLower(v)
+		}
+		result.Params[k] = v
+	}
+	return result, true
+}
+
+// suffix returns the structured syntax suffix of a subtype, like json for vnd.api+json
+func (m mediaType) suffix() string {
+	if idx := strings.LastIndex(m.Subtype, "+"); idx >= 0 {
+		return m.Subtype[idx+1:]
+	}
+	return ""
+}
+
+// match returns how specific a media range matches a media type.
+// The parameters only prevent a match when both have a parameter with a different value,
+// a structured syntax suffix matches the subtype it stands for, so application/vnd.api+json matches application/json.
+func (m mediaType) match(other mediaType) int {
+	for k, v := range m.Params {
+		if ov, ok := other.Params[k]; ok && ov != v {
+			return noMatch
+		}
+	}
+
+	switch {
+	case m.Type == "*" && m.Subtype == "*":
+		return anyMatch
+	case m.Type != other.Type:
+		return noMatch
+	case m.Subtype == "*":
+		return typeMatch
+	case m.Subtype == other.Subtype:
+		return exactMatch
+	case m.suffix() != "" && m.suffix() == other.Subtype,
+		other.suffix() != "" && other.suffix() == m.Subtype:
+		return suffixMatch
+	}
+	return noMatch
+}
+
+// parseAccept parses the media ranges of an Accept header, the ranges that can't be parsed are skipped
+func parseAccept(header http.Header) []mediaType {
+	var result []mediaType
+	for _, value := range header[http.CanonicalHeaderKey(HeaderAccept)] {
+		for _, rng := range strings.Split(value, ",") {
+			if strings.TrimSpace(rng) == "" {
+				continue
+			}
+			if mt, ok := parseMediaType(rng); ok {
+				result = append(result, mt)
+			}
+		}
+	}
+	return result
+}
+
+// NegotiateContentType returns the best offered media type for the Accept header of a request.
+// The q value of an offer comes from the most specific media range that matches it,
+// between offers with the same q value the most specific match wins and then the first offer.
+// When the request has no Accept header the first offer is returned,
+// when no offer is acceptable the default offer is returned.
+func NegotiateContentType(r *http.Request, offers []string, defaultOffer string) string {
+	ranges := parseAccept(r.Header)
+	if len(ranges) == 0 {
+		if len(offers) > 0 {
+			return offers[0]
+		}
+		return defaultOffer
+	}
+
+	best, bestQ, bestMatch := defaultOffer, 0.0, noMatch
+	for _, offer := range offers {
+		om, ok := parseMediaType(offer)
+		if !ok {
+			continue
+		}
+		q, specificity := 0.0, noMatch
+		for _, rng := range ranges {
+			if m := rng.match(om); m > specificity || (m == specificity && m != noMatch && len(rng.Params) > 0) {
+				q, specificity = rng.Q, m
+			}
+		}
+		if specificity == noMatch || q == 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && specificity > bestMatch) {
+			best, bestQ, bestMatch = offer, q, specificity
+		}
+	}
+	return best
+}
+
+// MatchContentType returns the allowed media type that matches the media type of a request body,
+// it follows the same rules as the negotiation of the response format
+func MatchContentType(allowed []string, actual string) (string, bool) {
+	am, ok := parseMediaType(actual)
+	if !ok || am.Type == "*" || am.Subtype == "*" {
+		return "", false
+	}
+
+	best, bestMatch := "", noMatch
+	for _, value := range allowed {
+		mt, ok := parseMediaType(value)
+		if !ok {
+			continue
+		}
+		if m := mt.match(am); m > bestMatch {
+			best, bestMatch = value, m
+		}
+	}
+	return best, bestMatch != noMatch
+}
+
+// AddVary adds a header to the Vary header of a response when it isn't there yet
+func AddVary(header http.Header, name string) {
+	for _, value := range header[HeaderVary] {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), name) {
+				return
+			}
+		}
+	}
+	header.Add(HeaderVary, name)
+}
@@ -0,0 +1,84 @@
+package httpkit
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "application/x-yaml", "text/html"}
+	data := []struct {
+		accept   string
+		offers   []string
+		expected string
+	}{
+		{"", offers, "application/json"},
+		{"application/x-yaml", offers, "application/x-yaml"},
+		{"*/*", offers, "application/json"},
+		{"text/*", offers, "text/html"},
+		{"application/*;q=0.5, text/html", offers, "text/html"},
+		{"application/json;q=0.2, application/x-yaml;q=0.8", offers, "application/x-yaml"},
+		{"application/json;q=0, */*", offers, "application/x-yaml"},
+		{"application/json;q=0.5, */*;q=0.9", offers, "application/x-yaml"},
+		{"*/*;q=0.9, application/json", offers, "application/json"},
+		{"text/html,application/json", offers, "application/json"},
+		{"application/sgml", offers, "default"},
+		{"application/json;q=0", offers, "default"},
+		{"application/vnd.api+json", offers, "application/json"},
+		{"application/json", []string{"application/vnd.api+json"}, "application/vnd.api+json"},
+		{"application/vnd.api+json, application/json;q=0.5", []string{"application/json", "application/vnd.api+json"}, "application/vnd.api+json"},
+		{"application/json; version=2", []string{"application/json; version=1", "application/json; version=2"}, "application/json; version=2"},
+		{"application/json; version=3", []string{"application/json; version=1"}, "default"},
+		{"text/html; charset=UTF-8", []string{"text/html; charset=utf-8"}, "text/html; charset=utf-8"},
+		{"text/html; charset=latin1", []string{"text/html; charset=utf-8"}, "default"},
+		{"application(, text/html", offers, "text/html"},
+		{"text/html;q=2", offers, "application/json"},
+	}
+
+	for _, v := range data {
+		request, _ := http.NewRequest("GET", "/", nil)
+		if v.accept != "" {
+			request.Header.Set(HeaderAccept, v.accept)
+		}
+		assert.Equal(t, v.expected, NegotiateContentType(request, v.offers, "default"), "accept: %q", v.accept)
+	}
+}
+
+func TestMatchContentType(t *testing.T) {
+	allowed := []string{"application/json", "text/*"}
+
+	mt, ok := MatchContentType(allowed, "application/json; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, "application/json", mt)
+
+	mt, ok = MatchContentType(allowed, "application/merge-patch+json")
+	assert.True(t, ok)
+	assert.Equal(t, "application/json", mt)
+
+	mt, ok = MatchContentType(allowed, "text/plain")
+	assert.True(t, ok)
+	assert.Equal(t, "text/*", mt)
+
+	mt, ok = MatchContentType([]string{"*/*", "text/plain"}, "text/plain")
+	assert.True(t, ok)
+	assert.Equal(t, "text/plain", mt)
+
+	_, ok = MatchContentType(allowed, "application/xml")
+	assert.False(t, ok)
+
+	_, ok = MatchContentType(allowed, "application(")
+	assert.False(t, ok)
+}
+
+func TestAddVary(t *testing.T) {
+	header := make(http.Header)
+	AddVary(header, HeaderAccept)
+	AddVary(header, "accept")
+	assert.Equal(t, []string{"Accept"}, header[HeaderVary])
+
+	header.Set(HeaderVary, "Origin, Accept-Encoding")
+	AddVary(header, HeaderAccept)
+	assert.Equal(t, []string{"Origin, Accept-Encoding", "Accept"}, header[HeaderVary])
+}
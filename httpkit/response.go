@@ -0,0 +1,26 @@
+package httpkit
+
+import "net/http"
+
+// Response is a result of an operation handler that picks the status code and headers of the response,
+// the status code needs to be one of the responses of the operation.
+// Without a status code the response gets the success status code of the operation.
+type Response struct {
+	Code    int
+	Headers http.Header
+	Payload interface{}
+}
+
+// NewResponse creates a response with the status code and payload
+func NewResponse(code int, payload interface{}) *Response {
+	return &Response{Code: code, Headers: make(http.Header), Payload: payload}
+}
+
+// WithHeader adds a header to the response
+func (r *Response) WithHeader(name, value string) *Response {
+	if r.Headers == nil {
+		r.Headers = make(http.Header)
+	}
+	r.Headers.Add(name, value)
+	return r
+}
@@ -91,6 +91,25 @@ func Required(path, in string, data interface{}) *errors.Validation {
 	return nil
 }
 
+// Mode is the direction of the data that is validated
+type Mode int
+
+const (
+	// ResponseMode validates data sent by the server, read only properties are validated like any other property
+	ResponseMode Mode = iota
+	// RequestMode validates data received by the server, read only properties are never required and can't be set
+	RequestMode
+)
+
+// ReadOnly validates that a read only value is not set
+func ReadOnly(path, in string, data interface{}) *errors.Validation {
+	val := reflect.ValueOf(data)
+	if val.IsValid() && !reflect.DeepEqual(reflect.Zero(val.Type()).Interface(), data) {
+		return errors.ReadOnly(path, in)
+	}
+	return nil
+}
+
 // RequiredString validates a string for requiredness
 func RequiredString(path, in, data string) *errors.Validation {
 	if data == "" {
@@ -20,6 +20,7 @@ type objectValidator struct {
 	PatternProperties    map[string]spec.Schema
 	Root                 interface{}
 	KnownFormats         strfmt.Registry
+	ReadOnly             ReadOnlyMode
 }
 
 func (o *objectValidator) SetPath(path string) {
@@ -49,6 +50,9 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 	res := new(Result)
 	if len(o.Required) > 0 {
 		for _, k := range o.Required {
+			if o.isReadOnly(k) {
+				continue
+			}
 			if _, ok := val[k]; !ok {
 				res.AddErrors(errors.Required(o.Path+"."+k, o.In))
 				continue
@@ -76,7 +80,7 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 			matched, succeededOnce, _ := o.validatePatternProperty(key, value, res)
 			if !(regularProperty || matched || succeededOnce) {
 				if o.AdditionalProperties != nil && o.AdditionalProperties.Schema != nil {
-					res.Merge(NewSchemaValidator(o.AdditionalProperties.Schema, o.Root, o.Path+"."+key, o.KnownFormats).Validate(value))
+					res.Merge(newSchemaValidator(o.AdditionalProperties.Schema, o.Root, o.Path+"."+key, o.KnownFormats, o.ReadOnly).Validate(value))
 				} else if regularProperty && !(matched || succeededOnce) {
 					res.AddErrors(errors.FailedAllPatternProperties(o.Path, o.In, key))
 				}
@@ -89,15 +93,36 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 		if o.Path != "" {
 			rName = o.Path + "." + pName
 		}
-		if v, ok := val[pName]; ok {
-			res.Merge(NewSchemaValidator(&pSchema, o.Root, rName, o.KnownFormats).Validate(v))
+		v, ok := val[pName]
+		if !ok {
+			continue
 		}
+		if o.isReadOnly(pName) {
+			switch o.ReadOnly {
+			case ReadOnlyRejected:
+				res.AddErrors(errors.ReadOnly(rName, o.In))
+				continue
+			case ReadOnlyStripped:
+				delete(val, pName)
+				continue
+			}
+		}
+		res.Merge(newSchemaValidator(&pSchema, o.Root, rName, o.KnownFormats, o.ReadOnly).Validate(v))
 	}
 
 	// Pattern Properties
 	return res
 }
 
+// isReadOnly returns true when the property is read only and read only properties are treated differently
+func (o *objectValidator) isReadOnly(name string) bool {
+	if o.ReadOnly == ReadOnlyIgnored {
+		return false
+	}
+	sch, ok := o.Properties[name]
+	return ok && sch.ReadOnly
+}
+
 func (o *objectValidator) validatePatternProperty(key string, value interface{}, result *Result) (bool, bool, []string) {
 	matched := false
 	succeededOnce := false
@@ -107,7 +132,7 @@ func (o *objectValidator) validatePatternProperty(key string, value interface{},
 		patterns = append(patterns, k)
 		if match, _ := regexp.MatchString(k, key); match {
 			matched = true
-			validator := NewSchemaValidator(&schema, o.Root, o.Path+"."+key, o.KnownFormats)
+			validator := newSchemaValidator(&schema, o.Root, o.Path+"."+key, o.KnownFormats, o.ReadOnly)
 
 			res := validator.Validate(value)
 			result.Merge(res)
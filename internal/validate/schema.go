@@ -10,6 +10,20 @@ import (
 
 var specSchemaType = reflect.TypeOf(&spec.Schema{})
 
+// ReadOnlyMode determines how properties marked as read only are validated
+type ReadOnlyMode int
+
+const (
+	// ReadOnlyIgnored validates read only properties like any other property, this is how a response is validated
+	ReadOnlyIgnored ReadOnlyMode = iota
+	// ReadOnlyOptional read only properties are never required, but they are validated when present
+	ReadOnlyOptional
+	// ReadOnlyRejected read only properties are never required and are an error when present
+	ReadOnlyRejected
+	// ReadOnlyStripped read only properties are never required and are removed from the data when present
+	ReadOnlyStripped
+)
+
 // SchemaValidator like param validator but for a full json schema
 type SchemaValidator struct {
 	Path         string
@@ -18,10 +32,22 @@ type SchemaValidator struct {
 	validators   []valueValidator
 	Root         interface{}
 	KnownFormats strfmt.Registry
+	ReadOnly     ReadOnlyMode
 }
 
 // NewSchemaValidator creates a new schema validator
 func NewSchemaValidator(schema *spec.Schema, rootSchema interface{}, root string, formats strfmt.Registry) *SchemaValidator {
+	return newSchemaValidator(schema, rootSchema, root, formats, ReadOnlyIgnored)
+}
+
+// NewRequestSchemaValidator creates a new schema validator for data received in a request,
+// the mode determines what happens with the read only properties in the data.
+// When the read only properties are stripped, they are deleted from the maps in the validated data.
+func NewRequestSchemaValidator(schema *spec.Schema, rootSchema interface{}, root string, formats strfmt.Registry, mode ReadOnlyMode) *SchemaValidator {
+	return newSchemaValidator(schema, rootSchema, root, formats, mode)
+}
+
+func newSchemaValidator(schema *spec.Schema, rootSchema interface{}, root string, formats strfmt.Registry, mode ReadOnlyMode) *SchemaValidator {
 	if schema == nil {
 		return nil
 	}
@@ -37,7 +63,7 @@ func NewSchemaValidator(schema *spec.Schema, rootSchema interface{}, root string
 		}
 	}
 
-	s := SchemaValidator{Path: root, in: "body", Schema: schema, Root: rootSchema, KnownFormats: formats}
+	s := SchemaValidator{Path: root, in: "body", Schema: schema, Root: rootSchema, KnownFormats: formats, ReadOnly: mode}
 	s.validators = []valueValidator{
 		s.typeValidator(),
 		s.schemaPropsValidator(),
@@ -119,6 +145,7 @@ func (s *SchemaValidator) sliceValidator() valueValidator {
 		Items:           s.Schema.Items,
 		Root:            s.Root,
 		KnownFormats:    s.KnownFormats,
+		ReadOnly:        s.ReadOnly,
 	}
 }
 
@@ -158,7 +185,7 @@ func (s *SchemaValidator) formatValidator() valueValidator {
 
 func (s *SchemaValidator) schemaPropsValidator() valueValidator {
 	sch := s.Schema
-	return newSchemaPropsValidator(s.Path, s.in, sch.AllOf, sch.OneOf, sch.AnyOf, sch.Not, sch.Dependencies, s.Root, s.KnownFormats)
+	return newSchemaPropsValidator(s.Path, s.in, sch.AllOf, sch.OneOf, sch.AnyOf, sch.Not, sch.Dependencies, s.Root, s.KnownFormats, s.ReadOnly)
 }
 
 func (s *SchemaValidator) objectValidator() valueValidator {
@@ -173,5 +200,6 @@ func (s *SchemaValidator) objectValidator() valueValidator {
 		PatternProperties:    s.Schema.PatternProperties,
 		Root:                 s.Root,
 		KnownFormats:         s.KnownFormats,
+		ReadOnly:             s.ReadOnly,
 	}
 }
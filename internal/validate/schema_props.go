@@ -22,29 +22,30 @@ type schemaPropsValidator struct {
 	notValidator    *SchemaValidator
 	Root            interface{}
 	KnownFormats    strfmt.Registry
+	ReadOnly        ReadOnlyMode
 }
 
 func (s *schemaPropsValidator) SetPath(path string) {
 	s.Path = path
 }
 
-func newSchemaPropsValidator(path string, in string, allOf, oneOf, anyOf []spec.Schema, not *spec.Schema, deps spec.Dependencies, root interface{}, formats strfmt.Registry) *schemaPropsValidator {
+func newSchemaPropsValidator(path string, in string, allOf, oneOf, anyOf []spec.Schema, not *spec.Schema, deps spec.Dependencies, root interface{}, formats strfmt.Registry, mode ReadOnlyMode) *schemaPropsValidator {
 	var anyValidators []SchemaValidator
 	for _, v := range anyOf {
-		anyValidators = append(anyValidators, *NewSchemaValidator(&v, root, path, formats))
+		anyValidators = append(anyValidators, *newSchemaValidator(&v, root, path, formats, mode))
 	}
 	var allValidators []SchemaValidator
 	for _, v := range allOf {
-		allValidators = append(allValidators, *NewSchemaValidator(&v, root, path, formats))
+		allValidators = append(allValidators, *newSchemaValidator(&v, root, path, formats, mode))
 	}
 	var oneValidators []SchemaValidator
 	for _, v := range oneOf {
-		oneValidators = append(oneValidators, *NewSchemaValidator(&v, root, path, formats))
+		oneValidators = append(oneValidators, *newSchemaValidator(&v, root, path, formats, mode))
 	}
 
 	var notValidator *SchemaValidator
 	if not != nil {
-		notValidator = NewSchemaValidator(not, root, path, formats)
+		notValidator = newSchemaValidator(not, root, path, formats, mode)
 	}
 
 	return &schemaPropsValidator{
@@ -61,6 +62,7 @@ func newSchemaPropsValidator(path string, in string, allOf, oneOf, anyOf []spec.
 		notValidator:    notValidator,
 		Root:            root,
 		KnownFormats:    formats,
+		ReadOnly:        mode,
 	}
 }
 
@@ -148,7 +150,7 @@ func (s *schemaPropsValidator) Validate(data interface{}) *Result {
 			if dep, ok := s.Dependencies[key]; ok {
 
 				if dep.Schema != nil {
-					mainResult.Merge(NewSchemaValidator(dep.Schema, s.Root, s.Path+"."+key, s.KnownFormats).Validate(data))
+					mainResult.Merge(newSchemaValidator(dep.Schema, s.Root, s.Path+"."+key, s.KnownFormats, s.ReadOnly).Validate(data))
 					continue
 				}
 
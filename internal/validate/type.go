@@ -126,15 +126,16 @@ func (t *typeValidator) Validate(data interface{}) *Result {
 	schType, format := t.schemaInfoForType(data)
 	isLowerInt := t.Format == "int64" && format == "int32"
 	isLowerFloat := t.Format == "float64" && format == "float32"
+	// numbers decoded from json are always float64, whole numbers are valid integers of any format
+	isFloatInt := schType == "number" && swag.IsFloat64AJSONInteger(val.Float()) && t.Type.Contains("integer")
 
-	if val.Kind() != reflect.String && t.Format != "" && !(format == t.Format || isLowerInt || isLowerFloat) {
+	if val.Kind() != reflect.String && t.Format != "" && !(format == t.Format || isLowerInt || isLowerFloat || isFloatInt) {
 		return sErr(errors.InvalidType(t.Path, t.In, t.Format, format))
 	}
 	if t.Format != "" && val.Kind() == reflect.String {
 		return result
 	}
 
-	isFloatInt := schType == "number" && swag.IsFloat64AJSONInteger(val.Float()) && t.Type.Contains("integer")
 	isIntFloat := schType == "integer" && t.Type.Contains("number")
 	if !(t.Type.Contains(schType) || isFloatInt || isIntFloat) {
 		return sErr(errors.InvalidType(t.Path, t.In, strings.Join(t.Type, ","), schType))
@@ -160,3 +160,17 @@ func TestJSONSchemaSuite(t *testing.T) {
 		}
 	})
 }
+
+func TestTypeValidatorJSONNumbers(t *testing.T) {
+	Convey("Numbers decoded from json", t, func() {
+		schema := new(spec.Schema).Typed("integer", "int64")
+
+		Convey("are valid integers when they are whole numbers", func() {
+			So(NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(float64(12)).HasErrors(), ShouldBeFalse)
+		})
+
+		Convey("are invalid integers when they have a fraction", func() {
+			So(NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(12.5).HasErrors(), ShouldBeTrue)
+		})
+	})
+}
@@ -26,6 +26,15 @@ func (e Extensions) GetString(key string) (string, bool) {
 	return "", false
 }
 
+// GetBool gets a boolean value from the extensions
+func (e Extensions) GetBool(key string) (bool, bool) {
+	if v, ok := e[strings.ToLower(key)]; ok {
+		b, ok := v.(bool)
+		return b, ok
+	}
+	return false, false
+}
+
 type vendorExtensible struct {
 	Extensions Extensions
 }
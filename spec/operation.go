@@ -30,17 +30,23 @@ type Operation struct {
 	operationProps
 }
 
-// SuccessResponse gets a success response model
+// SuccessResponse gets a success response model,
+// when there are several success responses the one with the lowest status code is returned
 func (o *Operation) SuccessResponse() (*Response, int, bool) {
 	if o.Responses == nil {
 		return nil, 0, false
 	}
 
-	for k, v := range o.Responses.StatusCodeResponses {
-		if k/100 == 2 {
-			return &v, k, true
+	code := 0
+	for k := range o.Responses.StatusCodeResponses {
+		if k/100 == 2 && (code == 0 || k < code) {
+			code = k
 		}
 	}
+	if code != 0 {
+		v := o.Responses.StatusCodeResponses[code]
+		return &v, code, true
+	}
 
 	return o.Responses.Default, 0, false
 }
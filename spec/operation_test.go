@@ -97,3 +97,28 @@ func TestIntegrationOperation(t *testing.T) {
 	})
 
 }
+
+func TestOperationSuccessResponse(t *testing.T) {
+
+	Convey("the success response of an operation should", t, func() {
+
+		Convey("be the 2xx response with the lowest status code", func() {
+			op := Operation{}
+			err := json.Unmarshal([]byte(`{"responses":{"204":{"description":"deleted"},"default":{"description":"error"},"202":{"description":"accepted"},"400":{"description":"bad"}}}`), &op)
+			So(err, ShouldBeNil)
+			resp, code, ok := op.SuccessResponse()
+			So(ok, ShouldBeTrue)
+			So(code, ShouldEqual, 202)
+			So(resp.Description, ShouldEqual, "accepted")
+		})
+
+		Convey("fall back to the default response", func() {
+			resp, code, ok := operation.SuccessResponse()
+			So(ok, ShouldBeFalse)
+			So(code, ShouldEqual, 0)
+			So(resp, ShouldEqual, operation.Responses.Default)
+		})
+
+	})
+
+}